@@ -0,0 +1,262 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"screenshoter/config"
+	"screenshoter/internal/options"
+	"screenshoter/internal/service"
+	"slices"
+	"sync"
+)
+
+// batchManifest описание пакетного рендера
+//
+//	{
+//	  "defaults": {"type": "png", "full_page": true, "viewport": {"width": 1280, "height": 720}},
+//	  "items": [
+//	    {"html_file": "card.html", "output": "card.png"},
+//	    {"url": "https://example.com", "output": "example.jpeg", "options": {"type": "jpeg"}}
+//	  ]
+//	}
+type batchManifest struct {
+	Defaults json.RawMessage `json:"defaults"`
+	Items    []batchItem     `json:"items"`
+}
+
+type batchItem struct {
+	HTMLFile string          `json:"html_file"`
+	HTML     string          `json:"html"`
+	URL      string          `json:"url"`
+	Output   string          `json:"output"`
+	Options  json.RawMessage `json:"options"`
+}
+
+// runBatch рендерит все элементы манифеста
+func runBatch(args []string) int {
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "configuration read error: %v\n", err)
+		return 1
+	}
+
+	fs := flag.NewFlagSet("batch", flag.ExitOnError)
+	workers := fs.Int("workers", cfg.MaxWorkers, "number of parallel renders")
+	_ = fs.Parse(args)
+
+	if fs.NArg() != 1 || *workers < 1 {
+		fmt.Fprintln(os.Stderr, "usage: screenshoter batch [-workers N] manifest.json")
+		return 2
+	}
+
+	manifestPath := fs.Arg(0)
+	data, err := os.ReadFile(manifestPath)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to read manifest: %v\n", err)
+		return 1
+	}
+	var manifest batchManifest
+	if err := json.Unmarshal(data, &manifest); err != nil {
+		fmt.Fprintf(os.Stderr, "invalid manifest: %v\n", err)
+		return 1
+	}
+
+	// Настройки по умолчанию совпадают с HTTP API
+	defaults := service.ScreenshotOptions{
+		Browser:        service.BrowserChromium,
+		Type:           cfg.Type,
+		FullPage:       cfg.FullPage,
+		Timeout:        float64(cfg.Timeout),
+		DocumentMode:   cfg.DocumentMode,
+		DocumentOrigin: cfg.DocumentOrigin,
+		MaxScrollSteps: cfg.MaxScrollSteps,
+		Emulation:      options.Emulation(cfg),
+		SelectionStyle: &service.SelectionStyle{
+			BorderColor: cfg.SelectionBorderColor,
+			BorderWidth: cfg.SelectionBorderWidth,
+			BorderStyle: cfg.SelectionBorderStyle,
+			Opacity:     cfg.SelectionBorderOpacity,
+		},
+		AnnotationStyle: &service.AnnotationStyle{
+			Color:       cfg.AnnotationColor,
+			TextColor:   cfg.AnnotationTextColor,
+			FontSize:    cfg.AnnotationFontSize,
+			StrokeWidth: cfg.AnnotationStrokeWidth,
+		},
+		SpotlightOpacity: cfg.SpotlightOpacity,
+	}
+	defaults.Watermark = options.Watermark(cfg.KeyWatermark(nil))
+	defaults.Proxy = options.Proxy(cfg.KeyProxy(nil))
+	if len(manifest.Defaults) > 0 {
+		if err := json.Unmarshal(manifest.Defaults, &defaults); err != nil {
+			fmt.Fprintf(os.Stderr, "invalid manifest defaults: %v\n", err)
+			return 1
+		}
+	}
+
+	screenshoter, err := newCLIPlaywright(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer screenshoter.Close()
+
+	baseDir := filepath.Dir(manifestPath)
+	jobs := make(chan int)
+	var failed int
+	var mu sync.Mutex
+	var wg sync.WaitGroup
+
+	for w := 0; w < *workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range jobs {
+				item := manifest.Items[i]
+				err := renderBatchItem(cfg, screenshoter, baseDir, item, defaults)
+
+				mu.Lock()
+				if err != nil {
+					failed++
+					fmt.Fprintf(os.Stderr, "item %d (%s): %v\n", i, item.Output, err)
+				} else {
+					fmt.Printf("item %d: %s\n", i, item.Output)
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	for i := range manifest.Items {
+		jobs <- i
+	}
+	close(jobs)
+	wg.Wait()
+
+	if failed > 0 {
+		fmt.Fprintf(os.Stderr, "%d of %d items failed\n", failed, len(manifest.Items))
+		return 1
+	}
+	return 0
+}
+
+// renderBatchItem рендерит один элемент манифеста, относительные пути считаются от каталога манифеста
+func renderBatchItem(cfg *config.Config, screenshoter *service.Playwright, baseDir string, item batchItem, defaults service.ScreenshotOptions) error {
+	if item.Output == "" {
+		return fmt.Errorf("output is required")
+	}
+
+	// Копируем вложенные значения, чтобы настройки элемента не изменили общие
+	opts := defaults
+	if defaults.Viewport != nil {
+		viewport := *defaults.Viewport
+		opts.Viewport = &viewport
+	}
+	if defaults.SelectionStyle != nil {
+		style := *defaults.SelectionStyle
+		opts.SelectionStyle = &style
+	}
+	if defaults.Watermark != nil {
+		watermark := *defaults.Watermark
+		opts.Watermark = &watermark
+	}
+	if defaults.Proxy != nil {
+		proxy := *defaults.Proxy
+		opts.Proxy = &proxy
+	}
+	if defaults.AnnotationStyle != nil {
+		style := *defaults.AnnotationStyle
+		opts.AnnotationStyle = &style
+	}
+	if defaults.Geolocation != nil {
+		geolocation := *defaults.Geolocation
+		opts.Geolocation = &geolocation
+	}
+	if defaults.ExtraHTTPHeaders != nil {
+		opts.ExtraHTTPHeaders = maps.Clone(defaults.ExtraHTTPHeaders)
+	}
+	opts.Cookies = slices.Clone(defaults.Cookies)
+	opts.Actions = slices.Clone(defaults.Actions)
+	opts.Selections = slices.Clone(defaults.Selections)
+	opts.Annotations = slices.Clone(defaults.Annotations)
+	if defaults.HTTPCredentials != nil {
+		credentials := *defaults.HTTPCredentials
+		opts.HTTPCredentials = &credentials
+	}
+	if defaults.Quality != nil {
+		quality := *defaults.Quality
+		opts.Quality = &quality
+	}
+	if len(item.Options) > 0 {
+		if err := json.Unmarshal(item.Options, &opts); err != nil {
+			return fmt.Errorf("invalid options: %w", err)
+		}
+	}
+	opts.URL = item.URL
+	browser, err := service.ParseBrowser(string(opts.Browser))
+	if err != nil {
+		return err
+	}
+	opts.Browser = browser
+	if err := opts.Emulation.Validate(); err != nil {
+		return err
+	}
+	if err := opts.Credentials.Validate(); err != nil {
+		return err
+	}
+	if err := service.ValidateActions(opts.Actions); err != nil {
+		return err
+	}
+	if err := service.ValidateSelections(opts.Selections); err != nil {
+		return err
+	}
+	if err := service.ValidateAnnotations(opts); err != nil {
+		return err
+	}
+	if err := service.ValidateAnimation(opts); err != nil {
+		return err
+	}
+	if opts.Watermark != nil {
+		if err := opts.Watermark.Validate(); err != nil {
+			return err
+		}
+	}
+	if opts.Proxy != nil {
+		if err := opts.Proxy.Validate(); err != nil {
+			return err
+		}
+	}
+	launch, err := options.Limits(cfg).Preset(opts)
+	if err != nil {
+		return err
+	}
+	opts.Launch = launch
+
+	html := item.HTML
+	if item.HTMLFile != "" {
+		if html, err = readHTML(resolvePath(baseDir, item.HTMLFile)); err != nil {
+			return err
+		}
+	}
+	if html == "" && opts.URL == "" {
+		return fmt.Errorf("one of html_file, html or url is required")
+	}
+
+	res, err := screenshoter.Make(context.Background(), html, opts)
+	if err != nil {
+		return err
+	}
+
+	return os.WriteFile(resolvePath(baseDir, item.Output), res.Image, 0644)
+}
+
+func resolvePath(baseDir, path string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
@@ -0,0 +1,411 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"screenshoter/config"
+	"screenshoter/internal/options"
+	"screenshoter/internal/service"
+	"screenshoter/pkg/logger"
+	"strconv"
+	"strings"
+)
+
+// selectionsFlag повторяемый флаг --selection x,y,width,height[,viewport|element=selector]
+type selectionsFlag []service.SelectionArea
+
+func (s *selectionsFlag) String() string {
+	return fmt.Sprint(*s)
+}
+
+func (s *selectionsFlag) Set(value string) error {
+	parts := strings.SplitN(value, ",", 5)
+	if len(parts) < 4 {
+		return fmt.Errorf("selection must be x,y,width,height[,viewport|element=selector]")
+	}
+	var v [4]int
+	for i, part := range parts[:4] {
+		n, err := strconv.Atoi(strings.TrimSpace(part))
+		if err != nil {
+			return fmt.Errorf("invalid selection value %q", part)
+		}
+		v[i] = n
+	}
+	selection := service.SelectionArea{X: v[0], Y: v[1], Width: v[2], Height: v[3]}
+	if len(parts) == 5 {
+		space, selector, _ := strings.Cut(parts[4], "=")
+		selection.Space, selection.Selector = strings.TrimSpace(space), selector
+	}
+	*s = append(*s, selection)
+	return nil
+}
+
+// geolocationFlag флаг --geolocation latitude,longitude[,accuracy]
+type geolocationFlag struct {
+	value *service.Geolocation
+}
+
+func (g *geolocationFlag) String() string {
+	if g.value == nil {
+		return ""
+	}
+	return fmt.Sprintf("%g,%g,%g", g.value.Latitude, g.value.Longitude, g.value.Accuracy)
+}
+
+func (g *geolocationFlag) Set(value string) error {
+	v, err := config.ParseGeolocation(value)
+	if err != nil {
+		return err
+	}
+	g.value = options.Geolocation(v)
+	return nil
+}
+
+// headersFlag повторяемый флаг --header "Name: value"
+type headersFlag map[string]service.Secret
+
+func (h headersFlag) String() string {
+	return fmt.Sprint(len(h), " headers")
+}
+
+func (h headersFlag) Set(value string) error {
+	name, v, ok := strings.Cut(value, ":")
+	if !ok || strings.TrimSpace(name) == "" {
+		return fmt.Errorf("header must be \"Name: value\"")
+	}
+	h[strings.TrimSpace(name)] = service.Secret(strings.TrimSpace(v))
+	return nil
+}
+
+// optionsFlags регистрирует флаги для всех полей ScreenshotOptions
+// и возвращает функцию, собирающую из них настройки скриншота
+func optionsFlags(fs *flag.FlagSet, cfg *config.Config) func() (service.ScreenshotOptions, error) {
+	browser := fs.String("browser", string(service.BrowserChromium), "browser: chromium, firefox or webkit")
+	typ := fs.String("type", cfg.Type, "output type: png, jpeg, gif or webm")
+	duration := fs.Float64("duration", 0, "gif and webm recording duration in milliseconds (0 - 3000)")
+	frameRate := fs.Int("frame-rate", 0, "gif frames per second (0 - 10)")
+	quality := fs.Int("quality", 0, "jpeg quality 0-100 (0 - browser default)")
+	fullPage := fs.Bool("full-page", cfg.FullPage, "capture the full scrollable page")
+	omitBackground := fs.Bool("omit-background", false, "hide default white background")
+	width := fs.Int("width", 0, "viewport width")
+	height := fs.Int("height", 0, "viewport height")
+	timeout := fs.Float64("timeout", float64(cfg.Timeout), "navigation timeout in milliseconds")
+	scrollX := fs.Int("scroll-x", 0, "horizontal scroll before capture")
+	scrollY := fs.Int("scroll-y", 0, "vertical scroll before capture")
+	failOnPageError := fs.Bool("fail-on-page-error", false, "fail when the page throws an uncaught error")
+	documentMode := fs.String("document-mode", cfg.DocumentMode, "load html via file:// (file) or an http origin (http)")
+	baseURL := fs.String("base-url", "", "serve html at this url so relative links resolve against the real site")
+	colorScheme := fs.String("color-scheme", cfg.ColorScheme, "emulated color scheme: light, dark or no-preference")
+	reducedMotion := fs.String("reduced-motion", cfg.ReducedMotion, "emulated reduced motion: reduce or no-preference")
+	locale := fs.String("locale", cfg.Locale, "browser locale, e.g. ru-RU")
+	timezoneID := fs.String("timezone-id", cfg.TimezoneID, "browser time zone, e.g. Europe/Moscow")
+	geolocation := geolocationFlag{value: options.Emulation(cfg).Geolocation}
+	fs.Var(&geolocation, "geolocation", "emulated position latitude,longitude[,accuracy]")
+	acceptLanguage := fs.String("accept-language", cfg.AcceptLanguage, "Accept-Language header")
+	media := fs.String("media", cfg.Media, "emulated media type: screen or print")
+	headers := headersFlag{}
+	fs.Var(headers, "header", "extra http header \"Name: value\" (repeatable)")
+	userAgent := fs.String("user-agent", "", "browser user agent")
+	httpCredentials := fs.String("http-credentials", "", "http basic credentials user:password")
+	storageStateFile := fs.String("storage-state", "", "playwright storage state file (cookies and localStorage)")
+	actionsFile := fs.String("actions", "", "JSON file with page actions to run before capture")
+	scrollContainer := fs.String("scroll-container", "", "selector of an element with its own scroll to capture step by step and stitch")
+	var selections selectionsFlag
+	fs.Var(&selections, "selection", "selection rectangle x,y,width,height[,viewport|element=selector] (repeatable)")
+	borderColor := fs.String("selection-color", cfg.SelectionBorderColor, "selection border color")
+	borderWidth := fs.Int("selection-width", cfg.SelectionBorderWidth, "selection border width")
+	borderStyle := fs.String("selection-style", cfg.SelectionBorderStyle, "selection border style: solid, dashed or dotted")
+	borderOpacity := fs.Float64("selection-opacity", cfg.SelectionBorderOpacity, "selection border opacity 0.0-1.0")
+	annotationsFile := fs.String("annotations", "", "JSON file with arrows, callouts and markers to draw over the page")
+	spotlight := fs.Bool("spotlight", false, "dim everything outside the selections")
+	spotlightOpacity := fs.Float64("spotlight-opacity", cfg.SpotlightOpacity, "spotlight dimming opacity 0.0-1.0")
+	watermarkText := fs.String("watermark-text", cfg.WatermarkText, "watermark text")
+	watermarkImage := fs.String("watermark-image", cfg.WatermarkImage, "watermark PNG file instead of text")
+	watermarkPosition := fs.String("watermark-position", cfg.WatermarkPosition, "watermark position: top-left, top-right, bottom-left, bottom-right or center")
+	watermarkOpacity := fs.Float64("watermark-opacity", cfg.WatermarkOpacity, "watermark opacity 0.0-1.0")
+	watermarkTile := fs.Bool("watermark-tile", cfg.WatermarkTile, "repeat the watermark over the whole image")
+	watermarkMargin := fs.Int("watermark-margin", cfg.WatermarkMargin, "watermark margin from the edge or between tiles")
+	noWatermark := fs.Bool("no-watermark", false, "do not apply the configured watermark")
+	proxyServer := fs.String("proxy-server", cfg.ProxyServer, "proxy for browser requests: host:port or scheme://host:port")
+	proxyBypass := fs.String("proxy-bypass", cfg.ProxyBypass, "comma-separated domains to bypass the proxy")
+	proxyUsername := fs.String("proxy-username", cfg.ProxyUsername, "proxy username")
+	proxyPassword := fs.String("proxy-password", cfg.ProxyPassword, "proxy password")
+	launchPreset := fs.String("launch-preset", "", "browser launch preset from launch_presets (empty - launch_preset)")
+
+	return func() (service.ScreenshotOptions, error) {
+		browser, err := service.ParseBrowser(*browser)
+		if err != nil {
+			return service.ScreenshotOptions{}, err
+		}
+		opts := service.ScreenshotOptions{
+			Browser:        browser,
+			Type:           *typ,
+			FullPage:       *fullPage,
+			OmitBackground: *omitBackground,
+			Timeout:        *timeout,
+			Selections:     selections,
+			SelectionStyle: &service.SelectionStyle{
+				BorderColor: *borderColor,
+				BorderWidth: *borderWidth,
+				BorderStyle: *borderStyle,
+				Opacity:     *borderOpacity,
+			},
+			AnnotationStyle: &service.AnnotationStyle{
+				Color:       cfg.AnnotationColor,
+				TextColor:   cfg.AnnotationTextColor,
+				FontSize:    cfg.AnnotationFontSize,
+				StrokeWidth: cfg.AnnotationStrokeWidth,
+			},
+			Duration:         *duration,
+			FrameRate:        *frameRate,
+			Spotlight:        *spotlight,
+			SpotlightOpacity: *spotlightOpacity,
+			ScrollX:          *scrollX,
+			ScrollY:          *scrollY,
+			ScrollContainer:  *scrollContainer,
+			MaxScrollSteps:   cfg.MaxScrollSteps,
+			LaunchPreset:     *launchPreset,
+			FailOnPageError:  *failOnPageError,
+			DocumentMode:     *documentMode,
+			BaseURL:          *baseURL,
+			DocumentOrigin:   cfg.DocumentOrigin,
+			Emulation: service.Emulation{
+				ColorScheme:    *colorScheme,
+				ReducedMotion:  *reducedMotion,
+				Locale:         *locale,
+				TimezoneID:     *timezoneID,
+				AcceptLanguage: *acceptLanguage,
+				Geolocation:    geolocation.value,
+				Media:          *media,
+			},
+		}
+		if len(headers) > 0 {
+			opts.ExtraHTTPHeaders = headers
+		}
+		opts.UserAgent = *userAgent
+		if *httpCredentials != "" {
+			username, password, _ := strings.Cut(*httpCredentials, ":")
+			opts.HTTPCredentials = &service.HTTPCredentials{Username: username, Password: service.Secret(password)}
+		}
+		if *storageStateFile != "" {
+			state, err := os.ReadFile(*storageStateFile)
+			if err != nil {
+				return opts, fmt.Errorf("failed to read storage state: %w", err)
+			}
+			opts.StorageState = service.Secret(state)
+		}
+		if *actionsFile != "" {
+			data, err := os.ReadFile(*actionsFile)
+			if err != nil {
+				return opts, fmt.Errorf("failed to read actions: %w", err)
+			}
+			if err := json.Unmarshal(data, &opts.Actions); err != nil {
+				return opts, fmt.Errorf("invalid actions: %w", err)
+			}
+		}
+		if *annotationsFile != "" {
+			data, err := os.ReadFile(*annotationsFile)
+			if err != nil {
+				return opts, fmt.Errorf("failed to read annotations: %w", err)
+			}
+			if err := json.Unmarshal(data, &opts.Annotations); err != nil {
+				return opts, fmt.Errorf("invalid annotations: %w", err)
+			}
+		}
+		style := cfg.WatermarkStyle()
+		watermark := &config.Watermark{Text: *watermarkText, Image: *watermarkImage, Position: *watermarkPosition,
+			Opacity: *watermarkOpacity, Tile: watermarkTile, Margin: *watermarkMargin, Color: style.Color, FontSize: style.FontSize}
+		if !*noWatermark && watermark.Enabled() {
+			if watermark.Image != "" {
+				data, err := os.ReadFile(watermark.Image)
+				if err != nil {
+					return opts, fmt.Errorf("failed to read watermark image: %w", err)
+				}
+				watermark.Data = data
+			}
+			w := options.Watermark(watermark)
+			if err := w.Validate(); err != nil {
+				return opts, err
+			}
+			opts.Watermark = w
+		}
+		if *quality > 0 {
+			opts.Quality = quality
+		}
+		if *width > 0 || *height > 0 {
+			opts.Viewport = &struct {
+				Width  int `json:"width"`
+				Height int `json:"height"`
+			}{Width: *width, Height: *height}
+		}
+		if *proxyServer != "" {
+			opts.Proxy = options.Proxy(&config.Proxy{Server: *proxyServer, Bypass: *proxyBypass, Username: *proxyUsername, Password: *proxyPassword})
+			if err := opts.Proxy.Validate(); err != nil {
+				return opts, err
+			}
+		}
+		opts.Launch, err = options.Limits(cfg).Preset(opts)
+		return opts, err
+	}
+}
+
+// newCLIPlaywright запускает playwright для режимов командной строки
+func newCLIPlaywright(cfg *config.Config) (*service.Playwright, error) {
+	return service.NewPlaywright(logger.NewLogger(cfg))
+}
+
+// runCapture делает один скриншот и сохраняет его в файл
+func runCapture(args []string) int {
+	cfg, err := config.ReadConfig()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "configuration read error: %v\n", err)
+		return 1
+	}
+
+	fs := flag.NewFlagSet("capture", flag.ExitOnError)
+	htmlFile := fs.String("html", "", "html file to render (\"-\" for stdin)")
+	markdownFile := fs.String("markdown", "", "markdown file to render (\"-\" for stdin)")
+	textFile := fs.String("text", "", "plain text file to render (\"-\" for stdin)")
+	codeTheme := fs.String("code-theme", cfg.CodeTheme, "markdown code highlighting theme")
+	url := fs.String("url", "", "url to render")
+	output := fs.String("o", "", "output file")
+	parseOptions := optionsFlags(fs, cfg)
+	_ = fs.Parse(args)
+
+	inputs := 0
+	for _, v := range []string{*htmlFile, *markdownFile, *textFile, *url} {
+		if v != "" {
+			inputs++
+		}
+	}
+	if inputs != 1 {
+		fmt.Fprintln(os.Stderr, "exactly one of --html, --markdown, --text or --url is required")
+		return 2
+	}
+	if *output == "" {
+		fmt.Fprintln(os.Stderr, "output file (-o) is required")
+		return 2
+	}
+
+	var html string
+	switch {
+	case *htmlFile != "":
+		html, err = readHTML(*htmlFile)
+	case *markdownFile != "":
+		html, err = readMarkup(cfg, *markdownFile, *codeTheme, service.MarkdownToHTML)
+	case *textFile != "":
+		html, err = readMarkup(cfg, *textFile, *codeTheme, service.TextToHTML)
+	}
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+
+	opts, err := parseOptions()
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	opts.URL = *url
+	if err := opts.Emulation.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if err := opts.Credentials.Validate(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if err := service.ValidateActions(opts.Actions); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if err := service.ValidateSelections(opts.Selections); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if err := service.ValidateAnnotations(opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	if err := service.ValidateAnimation(opts); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	// Браузер запускается после проверки параметров, чтобы ошибка в флагах не ждала playwright
+	screenshoter, err := newCLIPlaywright(cfg)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer screenshoter.Close()
+
+	res, err := screenshoter.Make(context.Background(), html, opts)
+	var pageErr *service.PageError
+	if errors.As(err, &pageErr) {
+		printDiagnostics(pageErr.Diagnostics)
+	}
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "capture failed: %v\n", err)
+		return 1
+	}
+	printDiagnostics(res.Diagnostics)
+	if err := os.WriteFile(*output, res.Image, 0644); err != nil {
+		fmt.Fprintf(os.Stderr, "failed to write %s: %v\n", *output, err)
+		return 1
+	}
+
+	return 0
+}
+
+// printDiagnostics выводит ошибки страницы и неудачные запросы в stderr
+func printDiagnostics(d service.Diagnostics) {
+	for _, e := range d.PageErrors {
+		fmt.Fprintf(os.Stderr, "page error: %s\n", e)
+	}
+	for _, r := range d.FailedRequests {
+		if r.Status > 0 {
+			fmt.Fprintf(os.Stderr, "request failed: %s %s: status %d\n", r.Method, r.URL, r.Status)
+			continue
+		}
+		fmt.Fprintf(os.Stderr, "request failed: %s %s: %s\n", r.Method, r.URL, r.Error)
+	}
+}
+
+// readHTML читает html из файла или stdin
+func readHTML(path string) (string, error) {
+	if path == "-" {
+		data, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return "", fmt.Errorf("failed to read stdin: %w", err)
+		}
+		return string(data), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", fmt.Errorf("failed to read html file: %w", err)
+	}
+	return string(data), nil
+}
+
+// readMarkup читает markdown или текст и преобразует в html с оформлением из конфигурации
+func readMarkup(cfg *config.Config, path, codeTheme string, convert func(string, service.MarkupStyle) (string, error)) (string, error) {
+	src, err := readHTML(path)
+	if err != nil {
+		return "", err
+	}
+	style := service.MarkupStyle{CodeTheme: codeTheme}
+	if cfg.MarkupStylesheet != "" {
+		css, err := os.ReadFile(cfg.MarkupStylesheet)
+		if err != nil {
+			return "", fmt.Errorf("failed to read markup stylesheet: %w", err)
+		}
+		style.Stylesheet = string(css)
+	}
+	return convert(src, style)
+}
@@ -1,84 +1,37 @@
 package main
 
 import (
-	"context"
-	"github.com/getsentry/sentry-go"
-	"log"
-	"net/http"
+	"fmt"
 	"os"
-	"os/signal"
-	"screenshoter/config"
-	"screenshoter/internal/handlers"
-	"screenshoter/internal/service"
-	"screenshoter/pkg/httpserver"
-	"screenshoter/pkg/logger"
-	"syscall"
-	"time"
 )
 
-func main() {
-
-	// Config
-	cfg, err := config.ReadConfig()
-	if err != nil {
-		log.Fatalf("configuration read error: %v", err)
-	}
+const usage = `Usage: screenshoter <command> [flags]
 
-	// Logger
-	lgr := logger.NewLogger(cfg)
+Commands:
+  serve                     start HTTP server (default)
+  capture [flags] -o FILE   render a single html file or url
+  batch MANIFEST            render all items from a json manifest
 
-	lgr.Info().
-		Str("version", config.Version).
-		Msg("Starting screenshoter")
+Run "screenshoter <command> -h" to see command flags.
+`
 
-	// Sentry
-	if cfg.LogTarget == "sentry" {
-		err := sentry.Init(sentry.ClientOptions{
-			Dsn:         cfg.SentryDsn,
-			Environment: "production",
-			//Debug:       true,
-			Release: "Screenshoter@" + config.Version,
-		})
-		if err != nil {
-			lgr.Fatal().Err(err).Msgf("sentry.Init %s", err.Error())
-		}
-		lgr.Info().Msg("Sentry initialized")
-		defer sentry.Flush(2 * time.Second)
-	}
-
-	screenshoter, err := service.NewPlaywright(lgr)
-	if err != nil {
-		lgr.Fatal().Err(err).Msgf("Failed to initialize Playwright")
-	}
-	s := service.NewService(screenshoter)
-	h := handlers.NewHandler(s, cfg)
-	srv := httpserver.NewServer()
-
-	serverErr := make(chan error, 1)
-	go func() {
-		lgr.Info().Str("port", cfg.Port).Msg("Starting HTTP server")
-		if err := srv.Run(cfg.Port, h.InitRoutes()); err != nil && err != http.ErrServerClosed {
-			serverErr <- err
-		}
-	}()
-
-	quit := make(chan os.Signal, 1)
-	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
-	select {
-	case err := <-serverErr:
-		lgr.Error().Err(err).Msg("Server runtime error")
-	case sig := <-quit:
-		lgr.Info().Str("signal", sig.String()).Msg("Received shutdown signal")
+func main() {
+	command, args := "serve", os.Args[1:]
+	if len(args) > 0 && args[0] != "" && args[0][0] != '-' {
+		command, args = args[0], args[1:]
 	}
 
-	// Graceful shutdown
-	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
-	defer cancel()
-
-	lgr.Info().Msg("Shutting down server...")
-	if err := srv.Stop(ctx); err != nil {
-		lgr.Error().Err(err).Msg("Server shutdown error")
+	switch command {
+	case "serve":
+		runServe(args)
+	case "capture":
+		os.Exit(runCapture(args))
+	case "batch":
+		os.Exit(runBatch(args))
+	case "help":
+		fmt.Print(usage)
+	default:
+		fmt.Fprintf(os.Stderr, "unknown command %q\n\n%s", command, usage)
+		os.Exit(2)
 	}
-
-	lgr.Info().Msg("Server stopped gracefully")
 }
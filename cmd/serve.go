@@ -0,0 +1,183 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"github.com/getsentry/sentry-go"
+	"github.com/rs/zerolog"
+	"google.golang.org/grpc"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"screenshoter/config"
+	"screenshoter/internal/grpcapi"
+	"screenshoter/internal/handlers"
+	"screenshoter/internal/service"
+	"screenshoter/internal/workerpool"
+	pb "screenshoter/pkg/api/screenshoter/v1"
+	"screenshoter/pkg/grpcserver"
+	"screenshoter/pkg/httpserver"
+	"screenshoter/pkg/logger"
+	"screenshoter/pkg/tracing"
+	"syscall"
+	"time"
+)
+
+// runServe запускает HTTP-сервер (режим по умолчанию)
+func runServe(args []string) {
+	fs := flag.NewFlagSet("serve", flag.ExitOnError)
+	configPath := fs.String("config", "", "YAML or TOML config file (default $SS_CONFIG_FILE)")
+	_ = fs.Parse(args)
+
+	// Config
+	cfg, err := config.Load(*configPath)
+	if err != nil {
+		log.Fatalf("configuration read error: %v", err)
+	}
+
+	// Logger
+	lgr := logger.NewLogger(cfg)
+
+	lgr.Info().
+		Str("version", config.Version).
+		Msg("Starting screenshoter")
+
+	// Sentry
+	if cfg.LogTarget == "sentry" {
+		err := sentry.Init(sentry.ClientOptions{
+			Dsn:         cfg.SentryDsn,
+			Environment: cfg.SentryEnvironment,
+			//Debug:       true,
+			Release:          "Screenshoter@" + config.Version,
+			AttachStacktrace: true,
+		})
+		if err != nil {
+			lgr.Fatal().Err(err).Msgf("sentry.Init %s", err.Error())
+		}
+		lgr.Info().Msg("Sentry initialized")
+		defer sentry.Flush(2 * time.Second)
+	}
+
+	// Трассировка OpenTelemetry
+	shutdownTracing, err := tracing.Init(context.Background(), cfg)
+	if err != nil {
+		lgr.Fatal().Err(err).Msg("Failed to initialize tracing")
+	}
+	defer func() {
+		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		if err := shutdownTracing(ctx); err != nil {
+			lgr.Error().Err(err).Msg("Tracing shutdown error")
+		}
+	}()
+	if cfg.TracingExporter != "none" {
+		lgr.Info().Str("exporter", cfg.TracingExporter).Msg("Tracing initialized")
+	}
+
+	screenshoter, err := service.NewPlaywright(lgr)
+	if err != nil {
+		lgr.Fatal().Err(err).Msgf("Failed to initialize Playwright")
+	}
+
+	// Шаблоны страниц перечитываются при изменении файлов и по SIGHUP
+	var templates *service.Templates
+	if cfg.TemplatesDir != "" {
+		if templates, err = service.NewTemplates(cfg.TemplatesDir); err != nil {
+			lgr.Fatal().Err(err).Msg("Failed to load templates")
+		}
+		templatesCtx, stopTemplates := context.WithCancel(context.Background())
+		defer stopTemplates()
+		go templates.Watch(templatesCtx, func(err error) {
+			if err != nil {
+				lgr.Error().Err(err).Msg("Templates reload rejected, keeping current templates")
+				return
+			}
+			lgr.Info().Str("dir", cfg.TemplatesDir).Msg("Templates reloaded")
+		})
+		lgr.Info().Str("dir", cfg.TemplatesDir).Int("count", len(templates.List())).Msg("Templates loaded")
+	}
+
+	s := service.NewService(screenshoter, service.NewImageDiff(), templates)
+	pool := workerpool.New(cfg.MaxWorkers)
+
+	// Перезагрузка конфигурации без перезапуска
+	cfgManager := config.NewManager(cfg)
+	cfgManager.OnReload(func(_, cur *config.Config) {
+		zerolog.SetGlobalLevel(zerolog.Level(cur.LogLevel))
+		pool.Resize(cur.MaxWorkers)
+	})
+	if cfg.ConfigFile != "" {
+		watchCtx, stopWatch := context.WithCancel(context.Background())
+		defer stopWatch()
+		go cfgManager.Watch(watchCtx, func(ignored []string, err error) {
+			if err != nil {
+				lgr.Error().Err(err).Msg("Config reload rejected, keeping current configuration")
+				return
+			}
+			if len(ignored) > 0 {
+				lgr.Warn().Strs("fields", ignored).Msg("Config changes require restart and were not applied")
+			}
+			lgr.Info().Str("file", cfg.ConfigFile).Msg("Configuration reloaded")
+		})
+		lgr.Info().Str("file", cfg.ConfigFile).Msg("Watching config file for changes")
+	}
+
+	h := handlers.NewHandler(s, cfgManager, pool, lgr)
+	srv := httpserver.NewServer()
+
+	serverErr := make(chan error, 2)
+	go func() {
+		lgr.Info().Str("port", cfg.Port).Msg("Starting HTTP server")
+		if err := srv.Run(cfg.Port, h.InitRoutes()); err != nil && err != http.ErrServerClosed {
+			serverErr <- err
+		}
+	}()
+
+	// gRPC API на отдельном порту
+	var grpcSrv *grpcserver.Server
+	if cfg.GrpcPort != "" {
+		grpcSrv = grpcserver.NewServer(
+			grpc.ChainUnaryInterceptor(grpcapi.UnaryAccessLogInterceptor(lgr), grpcapi.UnaryRecoveryInterceptor(lgr),
+				grpcapi.UnaryAuthInterceptor(cfgManager)),
+			grpc.ChainStreamInterceptor(grpcapi.StreamAccessLogInterceptor(lgr), grpcapi.StreamRecoveryInterceptor(lgr),
+				grpcapi.StreamAuthInterceptor(cfgManager)),
+		)
+		grpcAPI := grpcapi.NewServer(s, cfgManager, pool, lgr)
+		pb.RegisterScreenshoterServer(grpcSrv.Registrar(), grpcAPI)
+		jobsCtx, stopJobs := context.WithCancel(context.Background())
+		defer stopJobs()
+		go grpcAPI.ExpireJobs(jobsCtx)
+		go func() {
+			lgr.Info().Str("port", cfg.GrpcPort).Msg("Starting gRPC server")
+			if err := grpcSrv.Run(cfg.GrpcPort); err != nil {
+				serverErr <- err
+			}
+		}()
+	}
+
+	quit := make(chan os.Signal, 1)
+	signal.Notify(quit, syscall.SIGINT, syscall.SIGTERM, syscall.SIGQUIT)
+	select {
+	case err := <-serverErr:
+		lgr.Error().Err(err).Msg("Server runtime error")
+	case sig := <-quit:
+		lgr.Info().Str("signal", sig.String()).Msg("Received shutdown signal")
+	}
+
+	// Graceful shutdown
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Second)
+	defer cancel()
+
+	lgr.Info().Msg("Shutting down server...")
+	if err := srv.Stop(ctx); err != nil {
+		lgr.Error().Err(err).Msg("Server shutdown error")
+	}
+	if grpcSrv != nil {
+		if err := grpcSrv.Stop(ctx); err != nil {
+			lgr.Error().Err(err).Msg("gRPC server shutdown error")
+		}
+	}
+
+	lgr.Info().Msg("Server stopped gracefully")
+}
@@ -0,0 +1,85 @@
+package config
+
+import (
+	"fmt"
+	"image/color"
+	"strconv"
+	"strings"
+)
+
+// namedColors базовые именованные цвета CSS
+var namedColors = map[string]color.RGBA{
+	"black":   {0, 0, 0, 255},
+	"white":   {255, 255, 255, 255},
+	"red":     {255, 0, 0, 255},
+	"green":   {0, 128, 0, 255},
+	"lime":    {0, 255, 0, 255},
+	"blue":    {0, 0, 255, 255},
+	"yellow":  {255, 255, 0, 255},
+	"orange":  {255, 165, 0, 255},
+	"purple":  {128, 0, 128, 255},
+	"magenta": {255, 0, 255, 255},
+	"fuchsia": {255, 0, 255, 255},
+	"cyan":    {0, 255, 255, 255},
+	"aqua":    {0, 255, 255, 255},
+	"pink":    {255, 192, 203, 255},
+	"gray":    {128, 128, 128, 255},
+	"grey":    {128, 128, 128, 255},
+	"silver":  {192, 192, 192, 255},
+	"maroon":  {128, 0, 0, 255},
+	"navy":    {0, 0, 128, 255},
+	"olive":   {128, 128, 0, 255},
+	"teal":    {0, 128, 128, 255},
+}
+
+// ParseColor разбирает цвет в CSS-формате: имя, #rgb, #rrggbb, #rrggbbaa, rgb(), rgba()
+func ParseColor(s string) (color.RGBA, error) {
+	s = strings.ToLower(strings.TrimSpace(s))
+	if c, ok := namedColors[s]; ok {
+		return c, nil
+	}
+
+	if strings.HasPrefix(s, "#") {
+		hex := s[1:]
+		if len(hex) == 3 {
+			hex = string([]byte{hex[0], hex[0], hex[1], hex[1], hex[2], hex[2]})
+		}
+		if len(hex) == 6 {
+			hex += "ff"
+		}
+		if len(hex) != 8 {
+			return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+		}
+		v, err := strconv.ParseUint(hex, 16, 32)
+		if err != nil {
+			return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+		}
+		return color.RGBA{R: uint8(v >> 24), G: uint8(v >> 16), B: uint8(v >> 8), A: uint8(v)}, nil
+	}
+
+	if strings.HasPrefix(s, "rgb") {
+		start, end := strings.Index(s, "("), strings.LastIndex(s, ")")
+		if start < 0 || end < start {
+			return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+		}
+		parts := strings.Split(s[start+1:end], ",")
+		if len(parts) != 3 && len(parts) != 4 {
+			return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+		}
+		var rgba [4]uint8
+		rgba[3] = 255
+		for i, part := range parts {
+			v, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+			if err != nil {
+				return color.RGBA{}, fmt.Errorf("invalid color %q", s)
+			}
+			if i == 3 {
+				v *= 255
+			}
+			rgba[i] = uint8(min(max(v, 0), 255))
+		}
+		return color.RGBA{R: rgba[0], G: rgba[1], B: rgba[2], A: rgba[3]}, nil
+	}
+
+	return color.RGBA{}, fmt.Errorf("unsupported color %q", s)
+}
@@ -0,0 +1,67 @@
+package config
+
+import (
+	"image/color"
+	"testing"
+)
+
+func TestParseColor(t *testing.T) {
+	tests := []struct {
+		in   string
+		want color.RGBA
+		ok   bool
+	}{
+		{"red", color.RGBA{255, 0, 0, 255}, true},
+		{" Navy ", color.RGBA{0, 0, 128, 255}, true},
+		{"#f80", color.RGBA{255, 136, 0, 255}, true},
+		{"#FF8800", color.RGBA{255, 136, 0, 255}, true},
+		{"#ff880080", color.RGBA{255, 136, 0, 128}, true},
+		{"rgb(10, 20, 30)", color.RGBA{10, 20, 30, 255}, true},
+		{"rgba(10,20,30,0.5)", color.RGBA{10, 20, 30, 127}, true},
+		{"rgb(300, -5, 30)", color.RGBA{255, 0, 30, 255}, true},
+		{"", color.RGBA{}, false},
+		{"rebeccapurple", color.RGBA{}, false},
+		{"hsl(120, 100%, 50%)", color.RGBA{}, false},
+		{"#ff88", color.RGBA{}, false},
+		{"#gg0000", color.RGBA{}, false},
+		{"rgb(1, 2)", color.RGBA{}, false},
+		{"rgb(a, b, c)", color.RGBA{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseColor(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParseColor(%q) error = %v, want ok %v", tt.in, err, tt.ok)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("ParseColor(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestValidateColors(t *testing.T) {
+	tests := []struct {
+		name string
+		edit func(c *Config)
+		ok   bool
+	}{
+		{name: "defaults", edit: func(c *Config) {}, ok: true},
+		{name: "unknown selection color", edit: func(c *Config) { c.SelectionBorderColor = "rebeccapurple" }},
+		{name: "unknown watermark color", edit: func(c *Config) { c.WatermarkColor = "hsl(0, 0%, 100%)" }},
+		{name: "unknown key watermark color", edit: func(c *Config) {
+			c.APIKeys = []APIKey{{Name: "a", Token: "a-token", Watermark: &Watermark{Text: "a", Color: "transparentish"}}}
+		}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg, err := Load("")
+			if err != nil {
+				t.Fatal(err)
+			}
+			tt.edit(cfg)
+			if err := cfg.Validate(); (err == nil) != tt.ok {
+				t.Errorf("Validate() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
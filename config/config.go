@@ -1,40 +1,738 @@
 package config
 
 import (
+	"bytes"
+	"crypto/subtle"
+	"errors"
 	"fmt"
 	"github.com/kelseyhightower/envconfig"
+	"github.com/pelletier/go-toml/v2"
+	"gopkg.in/yaml.v3"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
 )
 
 const Version = "1.0.0"
 
+// DefaultKeyName имя ключа, заданного через SS_ACCESSTOKEN
+const DefaultKeyName = "default"
+
+// ScopeDebug разрешает запись отладочных артефактов рендера (HAR, трасса playwright)
+const ScopeDebug = "debug"
+
+// ScopeWatermark разрешает заменять и отключать водяной знак ключа в запросе
+const ScopeWatermark = "watermark"
+
+// ScopeProxy разрешает заменять прокси ключа или сервера в запросе
+const ScopeProxy = "proxy"
+
+// knownScopes разрешения, которые можно выдать ключу доступа
+var knownScopes = []string{ScopeDebug, ScopeWatermark, ScopeProxy}
+
 type Config struct {
-	Port        string `required:"true" default:"8033"`
-	AccessToken string `required:"true" default:"secret"`
+	ConfigFile string `split_words:"true" yaml:"-" toml:"-"` // Путь к файлу конфигурации YAML/TOML
+
+	Port        string `required:"true" default:"8033" yaml:"port" toml:"port"`
+	GrpcPort    string `split_words:"true" yaml:"grpc_port" toml:"grpc_port"` // Порт gRPC API, пусто - gRPC отключен
+	AccessToken string `required:"true" default:"secret" yaml:"access_token" toml:"access_token"`
+	// AccessTokenScopes разрешения ключа SS_ACCESSTOKEN
+	AccessTokenScopes []string `split_words:"true" yaml:"access_token_scopes" toml:"access_token_scopes"`
+
+	// APIKeys дополнительные именованные ключи доступа, задаются только в файле
+	APIKeys []APIKey `ignored:"true" yaml:"api_keys" toml:"api_keys"`
+
+	LogLevel  int    `default:"1" yaml:"log_level" toml:"log_level"`
+	LogFormat string `default:"json" yaml:"log_format" toml:"log_format"`
+
+	LogTarget string `default:"local" yaml:"log_target" toml:"log_target"`
+	SentryDsn string `required:"false" yaml:"sentry_dsn" toml:"sentry_dsn"`
+	// SentryEnvironment окружение в событиях Sentry: production, staging, ...
+	SentryEnvironment string `default:"production" split_words:"true" yaml:"sentry_environment" toml:"sentry_environment"`
+
+	// Трассировка OpenTelemetry: none, otlp (OTLP/gRPC) или stdout (для локальной отладки)
+	TracingExporter    string  `default:"none" split_words:"true" yaml:"tracing_exporter" toml:"tracing_exporter"`
+	TracingSampleRatio float64 `default:"1" split_words:"true" yaml:"tracing_sample_ratio" toml:"tracing_sample_ratio"`
+	OtlpEndpoint       string  `split_words:"true" yaml:"otlp_endpoint" toml:"otlp_endpoint"` // host:port коллектора, пусто - OTEL_EXPORTER_OTLP_ENDPOINT
+	OtlpInsecure       bool    `split_words:"true" yaml:"otlp_insecure" toml:"otlp_insecure"`
+
+	MaxWorkers int `default:"5" yaml:"max_workers" toml:"max_workers"`
+	// QueueTimeout ожидание свободного воркера (мс), после него 429; 0 - не ждать
+	QueueTimeout int `default:"5000" split_words:"true" yaml:"queue_timeout" toml:"queue_timeout"`
 
-	LogLevel  int    `default:"1"`
-	LogFormat string `default:"json"`
+	Type     string `default:"png" yaml:"type" toml:"type"`
+	Timeout  int    `default:"5000" yaml:"timeout" toml:"timeout"`                        // Таймаут загрузки страницы по умолчанию (мс)
+	FullPage bool   `default:"true" split_words:"true" yaml:"full_page" toml:"full_page"` // Скриншот всей страницы по умолчанию
 
-	LogTarget string `default:"local"`
-	SentryDsn string `required:"false"`
+	// Загрузка html в браузер: file (file://) или http (по http-адресу синтетического origin)
+	DocumentMode   string `default:"file" split_words:"true" yaml:"document_mode" toml:"document_mode"`
+	DocumentOrigin string `default:"http://screenshoter.local" split_words:"true" yaml:"document_origin" toml:"document_origin"`
 
-	MaxWorkers int `default:"5"`
+	// Ограничения параметров запроса, 0 - без ограничения
+	MaxViewportWidth  int      `default:"3840" split_words:"true" yaml:"max_viewport_width" toml:"max_viewport_width"`
+	MaxViewportHeight int      `default:"2160" split_words:"true" yaml:"max_viewport_height" toml:"max_viewport_height"`
+	MaxFullPageHeight int      `default:"16384" split_words:"true" yaml:"max_full_page_height" toml:"max_full_page_height"`
+	MaxTimeout        int      `default:"30000" split_words:"true" yaml:"max_timeout" toml:"max_timeout"`
+	AllowedTypes      []string `default:"png,jpeg,jpg,gif,webm" split_words:"true" yaml:"allowed_types" toml:"allowed_types"`
+	AllowedBrowsers   []string `default:"chromium,firefox,webkit" split_words:"true" yaml:"allowed_browsers" toml:"allowed_browsers"`
+	MaxBundleSize     int64    `default:"52428800" split_words:"true" yaml:"max_bundle_size" toml:"max_bundle_size"` // Суммарный размер файлов набора (байт)
+	MaxBundleFiles    int      `default:"1000" split_words:"true" yaml:"max_bundle_files" toml:"max_bundle_files"`
+	MaxActions        int      `default:"50" split_words:"true" yaml:"max_actions" toml:"max_actions"` // Действий со страницей в запросе
+	MaxScrollSteps    int      `default:"50" split_words:"true" yaml:"max_scroll_steps" toml:"max_scroll_steps"`
+	// Анимация gif и webm: длительность (мс), частота кадров gif и размер результата (байт)
+	MaxAnimationDuration int `default:"10000" split_words:"true" yaml:"max_animation_duration" toml:"max_animation_duration"`
+	MaxFrameRate         int `default:"15" split_words:"true" yaml:"max_frame_rate" toml:"max_frame_rate"`
+	MaxAnimationSize     int `default:"20971520" split_words:"true" yaml:"max_animation_size" toml:"max_animation_size"`
 
-	Type string `default:"png"`
+	// Эмуляция настроек пользователя по умолчанию, пусто - настройки браузера
+	ColorScheme    string `split_words:"true" yaml:"color_scheme" toml:"color_scheme"`       // light, dark или no-preference
+	ReducedMotion  string `split_words:"true" yaml:"reduced_motion" toml:"reduced_motion"`   // reduce или no-preference
+	Locale         string `yaml:"locale" toml:"locale"`                                      // Например ru-RU
+	TimezoneID     string `split_words:"true" yaml:"timezone_id" toml:"timezone_id"`         // Например Europe/Moscow
+	AcceptLanguage string `split_words:"true" yaml:"accept_language" toml:"accept_language"` // Заголовок Accept-Language
+	Media          string `yaml:"media" toml:"media"`                                        // screen или print
+	Geolocation    string `yaml:"geolocation" toml:"geolocation"`                            // широта,долгота[,точность]
 
-	SelectionBorderColor   string  `default:"red" split_words:"true"`
-	SelectionBorderWidth   int     `default:"3" split_words:"true"`
-	SelectionBorderStyle   string  `default:"solid" split_words:"true"`
-	SelectionBorderOpacity float64 `default:"0.8" split_words:"true"`
+	// Оформление страниц из markdown и текста: файл CSS (пусто - встроенный) и тема подсветки кода chroma
+	MarkupStylesheet string `split_words:"true" yaml:"markup_stylesheet" toml:"markup_stylesheet"`
+	CodeTheme        string `default:"github" split_words:"true" yaml:"code_theme" toml:"code_theme"`
+
+	// Прокси для запросов браузера всех ключей без собственного proxy, пусто - без прокси
+	ProxyServer   string `split_words:"true" yaml:"proxy_server" toml:"proxy_server"` // http://host:port, https://host:port или socks5://host:port
+	ProxyBypass   string `split_words:"true" yaml:"proxy_bypass" toml:"proxy_bypass"` // Домены без прокси через запятую
+	ProxyUsername string `split_words:"true" yaml:"proxy_username" toml:"proxy_username"`
+	ProxyPassword string `split_words:"true" yaml:"proxy_password" toml:"proxy_password"`
+
+	// LaunchPresets пресеты запуска браузера, выбираются запросом по имени, задаются только в файле
+	LaunchPresets []LaunchPreset `ignored:"true" yaml:"launch_presets" toml:"launch_presets"`
+	// LaunchPreset пресет для запросов без launch_preset, пусто - браузер без дополнительных настроек
+	LaunchPreset string `split_words:"true" yaml:"launch_preset" toml:"launch_preset"`
+
+	// Директория html-шаблонов для /api/templates, пусто - шаблоны отключены
+	TemplatesDir string `split_words:"true" yaml:"templates_dir" toml:"templates_dir"`
+
+	SelectionBorderColor   string  `default:"red" split_words:"true" yaml:"selection_border_color" toml:"selection_border_color"`
+	SelectionBorderWidth   int     `default:"3" split_words:"true" yaml:"selection_border_width" toml:"selection_border_width"`
+	SelectionBorderStyle   string  `default:"solid" split_words:"true" yaml:"selection_border_style" toml:"selection_border_style"`
+	SelectionBorderOpacity float64 `default:"0.8" split_words:"true" yaml:"selection_border_opacity" toml:"selection_border_opacity"`
+
+	// Стиль аннотаций по умолчанию: стрелки, выноски, маркеры и затемнение вокруг выделений
+	AnnotationColor       string  `default:"red" split_words:"true" yaml:"annotation_color" toml:"annotation_color"`
+	AnnotationTextColor   string  `default:"white" split_words:"true" yaml:"annotation_text_color" toml:"annotation_text_color"`
+	AnnotationFontSize    int     `default:"14" split_words:"true" yaml:"annotation_font_size" toml:"annotation_font_size"`
+	AnnotationStrokeWidth int     `default:"3" split_words:"true" yaml:"annotation_stroke_width" toml:"annotation_stroke_width"`
+	SpotlightOpacity      float64 `default:"0.6" split_words:"true" yaml:"spotlight_opacity" toml:"spotlight_opacity"`
+
+	// Водяной знак всех ключей без собственного watermark: текст или путь к PNG, пусто - без знака
+	WatermarkText     string  `split_words:"true" yaml:"watermark_text" toml:"watermark_text"`
+	WatermarkImage    string  `split_words:"true" yaml:"watermark_image" toml:"watermark_image"`
+	WatermarkPosition string  `default:"bottom-right" split_words:"true" yaml:"watermark_position" toml:"watermark_position"`
+	WatermarkOpacity  float64 `default:"0.5" split_words:"true" yaml:"watermark_opacity" toml:"watermark_opacity"`
+	WatermarkTile     bool    `split_words:"true" yaml:"watermark_tile" toml:"watermark_tile"`
+	WatermarkMargin   int     `default:"16" split_words:"true" yaml:"watermark_margin" toml:"watermark_margin"`
+	WatermarkColor    string  `default:"white" split_words:"true" yaml:"watermark_color" toml:"watermark_color"`
+	WatermarkFontSize int     `default:"24" split_words:"true" yaml:"watermark_font_size" toml:"watermark_font_size"`
+	// WatermarkImageData содержимое WatermarkImage, читается при загрузке конфигурации
+	WatermarkImageData []byte `ignored:"true" yaml:"-" toml:"-"`
+}
+
+// APIKey именованный ключ доступа к API
+type APIKey struct {
+	Name   string   `yaml:"name" toml:"name"`
+	Token  string   `yaml:"token" toml:"token"`
+	Scopes []string `yaml:"scopes" toml:"scopes"` // Дополнительные разрешения: debug, watermark, proxy
+
+	// Watermark водяной знак ключа вместо общего watermark_*, пустой - без знака
+	Watermark *Watermark `yaml:"watermark" toml:"watermark"`
+	// Proxy прокси ключа вместо общего proxy_*, пустой - без прокси
+	Proxy *Proxy `yaml:"proxy" toml:"proxy"`
+}
+
+// Watermark водяной знак: текст или PNG. Незаданные поля оформления берутся из общих watermark_*
+type Watermark struct {
+	Text     string  `yaml:"text" toml:"text"`
+	Image    string  `yaml:"image" toml:"image"`       // Путь к PNG
+	Position string  `yaml:"position" toml:"position"` // top-left, top-right, bottom-left, bottom-right или center
+	Opacity  float64 `yaml:"opacity" toml:"opacity"`
+	Tile     *bool   `yaml:"tile" toml:"tile"` // Повторять по всему изображению, nil - как в общем оформлении
+	Margin   int     `yaml:"margin" toml:"margin"`
+	Color    string  `yaml:"color" toml:"color"`
+	FontSize int     `yaml:"font_size" toml:"font_size"`
+	Data     []byte  `yaml:"-" toml:"-"` // Содержимое PNG, читается при загрузке конфигурации
+}
+
+// LaunchPreset настройки запуска браузера
+type LaunchPreset struct {
+	Name              string   `yaml:"name" toml:"name"`
+	Browser           string   `yaml:"browser" toml:"browser"`                 // chromium, firefox или webkit, пусто - любой
+	Channel           string   `yaml:"channel" toml:"channel"`                 // Канал chromium: chrome, msedge, chrome-beta, ...
+	ExecutablePath    string   `yaml:"executable_path" toml:"executable_path"` // Путь к исполняемому файлу браузера
+	Args              []string `yaml:"args" toml:"args"`                       // Аргументы командной строки браузера
+	Proxy             *Proxy   `yaml:"proxy" toml:"proxy"`
+	IgnoreHTTPSErrors bool     `yaml:"ignore_https_errors" toml:"ignore_https_errors"`
+	JavaScriptEnabled *bool    `yaml:"javascript_enabled" toml:"javascript_enabled"` // Пусто - включен
+}
+
+// Geolocation координаты, которые получит страница через navigator.geolocation
+type Geolocation struct {
+	Latitude  float64
+	Longitude float64
+	Accuracy  float64 // Точность в метрах
+}
+
+// ParseGeolocation разбирает координаты в формате "широта,долгота[,точность]" и проверяет их диапазон
+func ParseGeolocation(value string) (*Geolocation, error) {
+	parts := strings.Split(value, ",")
+	if len(parts) < 2 || len(parts) > 3 {
+		return nil, fmt.Errorf("geolocation must be latitude,longitude[,accuracy]")
+	}
+	var v [3]float64
+	for i, part := range parts {
+		n, err := strconv.ParseFloat(strings.TrimSpace(part), 64)
+		if err != nil {
+			return nil, fmt.Errorf("geolocation must be latitude,longitude[,accuracy]")
+		}
+		v[i] = n
+	}
+	if v[0] < -90 || v[0] > 90 || v[1] < -180 || v[1] > 180 || v[2] < 0 {
+		return nil, fmt.Errorf("geolocation must have latitude -90..90, longitude -180..180 and non-negative accuracy")
+	}
+	return &Geolocation{Latitude: v[0], Longitude: v[1], Accuracy: v[2]}, nil
+}
+
+// Proxy прокси-сервер для запросов браузера
+type Proxy struct {
+	Server   string `yaml:"server" toml:"server"` // http://host:port, https://host:port или socks5://host:port
+	Bypass   string `yaml:"bypass" toml:"bypass"` // Домены без прокси через запятую
+	Username string `yaml:"username" toml:"username"`
+	Password string `yaml:"password" toml:"password"`
+}
+
+// chromiumChannels каналы, которые playwright умеет запускать вместо встроенного chromium
+var chromiumChannels = []string{"chromium", "chrome", "chrome-beta", "chrome-dev", "chrome-canary",
+	"msedge", "msedge-beta", "msedge-dev", "msedge-canary"}
+
+// Enabled задан ли текст или изображение знака
+func (w *Watermark) Enabled() bool {
+	return w != nil && (w.Text != "" || w.Image != "")
+}
+
+// HasScope есть ли у ключа разрешение
+func (k *APIKey) HasScope(scope string) bool {
+	return k != nil && slices.Contains(k.Scopes, scope)
+}
+
+// DirectProxy оператор отключил прокси для ключа пустым proxy: {}
+func (k *APIKey) DirectProxy() bool {
+	return k != nil && k.Proxy != nil && k.Proxy.Server == ""
 }
 
 // ReadConfig получить кофигурацию из переменных окружения
+// и файла, указанного в SS_CONFIG_FILE
 func ReadConfig() (*Config, error) {
-	config := &Config{}
+	return Load("")
+}
 
-	err := envconfig.Process("SS", config)
+// Load читает конфигурацию: значения по умолчанию < файл < переменные окружения.
+// Если path пустой, используется SS_CONFIG_FILE
+func Load(path string) (*Config, error) {
+	envConfig := &Config{}
+
+	err := envconfig.Process("SS", envConfig)
+	if err != nil {
+		return nil, fmt.Errorf("configs error: %w", err)
+	}
+
+	if path == "" {
+		path = envConfig.ConfigFile
+	}
+	if path == "" {
+		if err := envConfig.Validate(); err != nil {
+			return nil, err
+		}
+		if err := envConfig.loadWatermarkImages(); err != nil {
+			return nil, err
+		}
+		return envConfig, nil
+	}
+
+	config := *envConfig
+	if err := decodeFile(path, &config); err != nil {
+		return nil, err
+	}
+
+	// Явно заданные переменные окружения важнее файла
+	explicit, err := explicitEnvFields()
 	if err != nil {
 		return nil, fmt.Errorf("configs error: %w", err)
 	}
+	dst := reflect.ValueOf(&config).Elem()
+	src := reflect.ValueOf(envConfig).Elem()
+	for _, name := range explicit {
+		dst.FieldByName(name).Set(src.FieldByName(name))
+	}
+	config.ConfigFile = path
+
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	if err := config.loadWatermarkImages(); err != nil {
+		return nil, err
+	}
+
+	return &config, nil
+}
+
+// loadWatermarkImages читает PNG водяных знаков при загрузке конфигурации, чтобы не читать файлы на каждый запрос
+func (c *Config) loadWatermarkImages() error {
+	var err error
+	if c.WatermarkImage != "" {
+		if c.WatermarkImageData, err = os.ReadFile(c.WatermarkImage); err != nil {
+			return fmt.Errorf("invalid configuration: watermark image: %w", err)
+		}
+	}
+	for _, key := range c.APIKeys {
+		if key.Watermark == nil || key.Watermark.Image == "" {
+			continue
+		}
+		if key.Watermark.Data, err = os.ReadFile(key.Watermark.Image); err != nil {
+			return fmt.Errorf("invalid configuration: api key %q: watermark image: %w", key.Name, err)
+		}
+	}
+	return nil
+}
+
+// decodeFile накладывает значения из файла YAML или TOML на конфигурацию
+func decodeFile(path string, config *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return fmt.Errorf("failed to read config file: %w", err)
+	}
+
+	switch strings.ToLower(filepath.Ext(path)) {
+	case ".yaml", ".yml":
+		dec := yaml.NewDecoder(bytes.NewReader(data))
+		dec.KnownFields(true)
+		if err := dec.Decode(config); err != nil && !errors.Is(err, io.EOF) {
+			return fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	case ".toml":
+		dec := toml.NewDecoder(bytes.NewReader(data))
+		dec.DisallowUnknownFields()
+		if err := dec.Decode(config); err != nil {
+			return fmt.Errorf("invalid config file %s: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("unsupported config file format %q, use .yaml, .yml or .toml", filepath.Ext(path))
+	}
+
+	return nil
+}
+
+// explicitEnvFields имена полей конфигурации, для которых задана переменная окружения
+func explicitEnvFields() ([]string, error) {
+	var buf bytes.Buffer
+	if err := envconfig.Usagef("SS", &Config{}, &buf, "{{range .}}{{.Name}}\t{{.Key}}\t{{.Alt}}\n{{end}}"); err != nil {
+		return nil, err
+	}
+
+	var names []string
+	for _, line := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		parts := strings.Split(line, "\t")
+		if len(parts) != 3 {
+			continue
+		}
+		_, ok := os.LookupEnv(parts[1])
+		if !ok && parts[2] != "" {
+			_, ok = os.LookupEnv(parts[2])
+		}
+		if ok {
+			names = append(names, parts[0])
+		}
+	}
+
+	return names, nil
+}
+
+// Validate проверяет корректность конфигурации
+func (c *Config) Validate() error {
+	var errs []string
+
+	if c.Port == "" {
+		errs = append(errs, "port is required")
+	}
+	if c.AccessToken == "" && len(c.APIKeys) == 0 {
+		errs = append(errs, "access token or api keys are required")
+	}
+	if c.LogLevel < -1 || c.LogLevel > 7 {
+		errs = append(errs, fmt.Sprintf("log level %d must be between -1 and 7", c.LogLevel))
+	}
+	if c.LogFormat != "json" && c.LogFormat != "text" {
+		errs = append(errs, fmt.Sprintf("log format %q must be json or text", c.LogFormat))
+	}
+	if c.LogTarget != "local" && c.LogTarget != "sentry" {
+		errs = append(errs, fmt.Sprintf("log target %q must be local or sentry", c.LogTarget))
+	}
+	switch c.TracingExporter {
+	case "none", "otlp", "stdout":
+	default:
+		errs = append(errs, fmt.Sprintf("tracing exporter %q must be none, otlp or stdout", c.TracingExporter))
+	}
+	if c.TracingSampleRatio < 0 || c.TracingSampleRatio > 1 {
+		errs = append(errs, fmt.Sprintf("tracing sample ratio %v must be between 0 and 1", c.TracingSampleRatio))
+	}
+	if c.MaxWorkers < 1 {
+		errs = append(errs, fmt.Sprintf("max workers %d must be positive", c.MaxWorkers))
+	}
+	if c.QueueTimeout < 0 {
+		errs = append(errs, fmt.Sprintf("queue timeout %d must not be negative", c.QueueTimeout))
+	}
+	switch c.Type {
+	case "png", "jpeg", "jpg":
+	default:
+		errs = append(errs, fmt.Sprintf("type %q must be png or jpeg", c.Type))
+	}
+	if len(c.AllowedTypes) > 0 && !slices.ContainsFunc(c.AllowedTypes, func(t string) bool { return sameType(t, c.Type) }) {
+		errs = append(errs, fmt.Sprintf("type %q is not in allowed types", c.Type))
+	}
+	for _, t := range c.AllowedTypes {
+		if !slices.Contains([]string{"png", "jpeg", "jpg", "gif", "webm"}, t) {
+			errs = append(errs, fmt.Sprintf("allowed type %q must be png, jpeg, jpg, gif or webm", t))
+		}
+	}
+	for _, b := range c.AllowedBrowsers {
+		if b != "chromium" && b != "firefox" && b != "webkit" {
+			errs = append(errs, fmt.Sprintf("allowed browser %q must be chromium, firefox or webkit", b))
+		}
+	}
+	if c.DocumentMode != "file" && c.DocumentMode != "http" {
+		errs = append(errs, fmt.Sprintf("document mode %q must be file or http", c.DocumentMode))
+	}
+	if u, err := url.Parse(c.DocumentOrigin); err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" || strings.Trim(u.Path, "/") != "" {
+		errs = append(errs, fmt.Sprintf("document origin %q must be an http or https origin", c.DocumentOrigin))
+	}
+	switch c.ColorScheme {
+	case "", "light", "dark", "no-preference":
+	default:
+		errs = append(errs, fmt.Sprintf("color scheme %q must be light, dark or no-preference", c.ColorScheme))
+	}
+	switch c.ReducedMotion {
+	case "", "reduce", "no-preference":
+	default:
+		errs = append(errs, fmt.Sprintf("reduced motion %q must be reduce or no-preference", c.ReducedMotion))
+	}
+	switch c.Media {
+	case "", "screen", "print":
+	default:
+		errs = append(errs, fmt.Sprintf("media %q must be screen or print", c.Media))
+	}
+	if c.TimezoneID != "" {
+		if _, err := time.LoadLocation(c.TimezoneID); err != nil {
+			errs = append(errs, fmt.Sprintf("timezone id %q is not a valid IANA time zone", c.TimezoneID))
+		}
+	}
+	if c.Geolocation != "" {
+		if _, err := ParseGeolocation(c.Geolocation); err != nil {
+			errs = append(errs, err.Error())
+		}
+	}
+	if c.MarkupStylesheet != "" {
+		if _, err := os.Stat(c.MarkupStylesheet); err != nil {
+			errs = append(errs, fmt.Sprintf("markup stylesheet: %v", err))
+		}
+	}
+	if c.CodeTheme == "" {
+		errs = append(errs, "code theme is required")
+	}
+	if c.Timeout <= 0 {
+		errs = append(errs, fmt.Sprintf("timeout %d must be positive", c.Timeout))
+	}
+	if c.MaxTimeout > 0 && c.Timeout > c.MaxTimeout {
+		errs = append(errs, fmt.Sprintf("timeout %d exceeds max timeout %d", c.Timeout, c.MaxTimeout))
+	}
+	if c.MaxViewportWidth < 0 || c.MaxViewportHeight < 0 || c.MaxFullPageHeight < 0 || c.MaxTimeout < 0 ||
+		c.MaxBundleSize < 0 || c.MaxBundleFiles < 0 || c.MaxActions < 0 || c.MaxScrollSteps < 0 ||
+		c.MaxAnimationDuration < 0 || c.MaxFrameRate < 0 || c.MaxAnimationSize < 0 {
+		errs = append(errs, "limits must not be negative")
+	}
+	if c.SelectionBorderColor == "" {
+		errs = append(errs, "selection border color is required")
+	} else if _, err := ParseColor(c.SelectionBorderColor); err != nil {
+		errs = append(errs, fmt.Sprintf("selection border color: %v", err))
+	}
+	if c.SelectionBorderWidth < 0 {
+		errs = append(errs, fmt.Sprintf("selection border width %d must not be negative", c.SelectionBorderWidth))
+	}
+	switch c.SelectionBorderStyle {
+	case "solid", "dashed", "dotted":
+	default:
+		errs = append(errs, fmt.Sprintf("selection border style %q must be solid, dashed or dotted", c.SelectionBorderStyle))
+	}
+	if c.SelectionBorderOpacity < 0 || c.SelectionBorderOpacity > 1 {
+		errs = append(errs, fmt.Sprintf("selection border opacity %v must be between 0 and 1", c.SelectionBorderOpacity))
+	}
+	if c.AnnotationColor == "" || c.AnnotationTextColor == "" {
+		errs = append(errs, "annotation color and text color are required")
+	}
+	if c.AnnotationFontSize <= 0 || c.AnnotationStrokeWidth <= 0 {
+		errs = append(errs, "annotation font size and stroke width must be positive")
+	}
+	if c.SpotlightOpacity <= 0 || c.SpotlightOpacity > 1 {
+		errs = append(errs, fmt.Sprintf("spotlight opacity %v must be greater than 0 and at most 1", c.SpotlightOpacity))
+	}
+
+	watermark := c.WatermarkStyle()
+	watermark.Text, watermark.Image = c.WatermarkText, c.WatermarkImage
+	if err := validateWatermark(watermark); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	for _, scope := range c.AccessTokenScopes {
+		if !slices.Contains(knownScopes, scope) {
+			errs = append(errs, fmt.Sprintf("access token scope %q is unknown", scope))
+		}
+	}
+
+	if err := c.serverProxy().Validate(); err != nil {
+		errs = append(errs, err.Error())
+	}
+
+	presets := map[string]bool{}
+	for i, preset := range c.LaunchPresets {
+		switch {
+		case preset.Name == "":
+			errs = append(errs, fmt.Sprintf("launch preset #%d: name is required", i+1))
+		case presets[preset.Name]:
+			errs = append(errs, fmt.Sprintf("launch preset #%d: duplicate name %q", i+1, preset.Name))
+		}
+		if err := validateLaunchPreset(preset); err != nil {
+			errs = append(errs, fmt.Sprintf("launch preset %q: %v", preset.Name, err))
+		}
+		presets[preset.Name] = true
+	}
+	if c.LaunchPreset != "" && !presets[c.LaunchPreset] {
+		errs = append(errs, fmt.Sprintf("launch preset %q is not defined in launch_presets", c.LaunchPreset))
+	}
+
+	names := map[string]bool{DefaultKeyName: c.AccessToken != ""}
+	tokens := map[string]bool{c.AccessToken: c.AccessToken != ""}
+	for i, key := range c.APIKeys {
+		switch {
+		case key.Name == "" || key.Token == "":
+			errs = append(errs, fmt.Sprintf("api key #%d: name and token are required", i+1))
+		case names[key.Name]:
+			errs = append(errs, fmt.Sprintf("api key #%d: duplicate name %q", i+1, key.Name))
+		case tokens[key.Token]:
+			errs = append(errs, fmt.Sprintf("api key %q: duplicate token", key.Name))
+		}
+		for _, scope := range key.Scopes {
+			if !slices.Contains(knownScopes, scope) {
+				errs = append(errs, fmt.Sprintf("api key %q: unknown scope %q", key.Name, scope))
+			}
+		}
+		if key.Watermark != nil {
+			if err := validateWatermark(*key.Watermark); err != nil {
+				errs = append(errs, fmt.Sprintf("api key %q: %v", key.Name, err))
+			}
+		}
+		if key.Proxy != nil {
+			if err := key.Proxy.Validate(); err != nil {
+				errs = append(errs, fmt.Sprintf("api key %q: %v", key.Name, err))
+			}
+		}
+		names[key.Name], tokens[key.Token] = true, true
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid configuration: %s", strings.Join(errs, "; "))
+	}
+	return nil
+}
 
-	return config, nil
+// WatermarkStyle общее оформление водяного знака без текста и изображения
+func (c *Config) WatermarkStyle() Watermark {
+	tile := c.WatermarkTile
+	return Watermark{
+		Position: c.WatermarkPosition,
+		Opacity:  c.WatermarkOpacity,
+		Tile:     &tile,
+		Margin:   c.WatermarkMargin,
+		Color:    c.WatermarkColor,
+		FontSize: c.WatermarkFontSize,
+	}
+}
+
+// KeyWatermark водяной знак ключа с общим оформлением, nil - без знака
+func (c *Config) KeyWatermark(key *APIKey) *Watermark {
+	w := c.WatermarkStyle()
+	if key != nil && key.Watermark != nil {
+		if !key.Watermark.Enabled() {
+			return nil
+		}
+		own := *key.Watermark
+		w.Text, w.Image, w.Data = own.Text, own.Image, own.Data
+		if own.Position != "" {
+			w.Position = own.Position
+		}
+		if own.Opacity > 0 {
+			w.Opacity = own.Opacity
+		}
+		if own.Tile != nil {
+			w.Tile = own.Tile
+		}
+		if own.Margin > 0 {
+			w.Margin = own.Margin
+		}
+		if own.Color != "" {
+			w.Color = own.Color
+		}
+		if own.FontSize > 0 {
+			w.FontSize = own.FontSize
+		}
+		return &w
+	}
+	w.Text, w.Image, w.Data = c.WatermarkText, c.WatermarkImage, c.WatermarkImageData
+	if !w.Enabled() {
+		return nil
+	}
+	return &w
+}
+
+// KeyProxy прокси ключа или общий proxy_*, nil - без прокси
+func (c *Config) KeyProxy(key *APIKey) *Proxy {
+	p := c.serverProxy()
+	if key != nil && key.Proxy != nil {
+		p = *key.Proxy
+	}
+	if p.Server == "" {
+		return nil
+	}
+	return &p
+}
+
+// serverProxy общий прокси из proxy_*
+func (c *Config) serverProxy() Proxy {
+	return Proxy{Server: c.ProxyServer, Bypass: c.ProxyBypass, Username: c.ProxyUsername, Password: c.ProxyPassword}
+}
+
+// LookupKey ищет ключ доступа по токену
+func (c *Config) LookupKey(token string) (*APIKey, bool) {
+	if token == "" {
+		return nil, false
+	}
+	if c.AccessToken != "" && subtle.ConstantTimeCompare([]byte(token), []byte(c.AccessToken)) == 1 {
+		return &APIKey{Name: DefaultKeyName, Token: c.AccessToken, Scopes: c.AccessTokenScopes}, true
+	}
+	for i := range c.APIKeys {
+		if subtle.ConstantTimeCompare([]byte(token), []byte(c.APIKeys[i].Token)) == 1 {
+			return &c.APIKeys[i], true
+		}
+	}
+	return nil, false
+}
+
+// sameType совпадают ли форматы с учетом синонимов jpg и jpeg
+func sameType(a, b string) bool {
+	jpeg := func(t string) bool { return t == "jpeg" || t == "jpg" }
+	return a == b || jpeg(a) && jpeg(b)
+}
+
+// validateWatermark проверяет оформление водяного знака и наличие файла изображения
+func validateWatermark(w Watermark) error {
+	if w.Text != "" && w.Image != "" {
+		return fmt.Errorf("watermark text and image are mutually exclusive")
+	}
+	if w.Image != "" {
+		if _, err := os.Stat(w.Image); err != nil {
+			return fmt.Errorf("watermark image: %w", err)
+		}
+	}
+	switch w.Position {
+	case "", "top-left", "top-right", "bottom-left", "bottom-right", "center":
+	default:
+		return fmt.Errorf("watermark position %q must be top-left, top-right, bottom-left, bottom-right or center", w.Position)
+	}
+	if w.Opacity < 0 || w.Opacity > 1 {
+		return fmt.Errorf("watermark opacity %v must be between 0 and 1", w.Opacity)
+	}
+	if w.Margin < 0 || w.FontSize < 0 {
+		return fmt.Errorf("watermark margin and font size must not be negative")
+	}
+	if w.Color != "" {
+		if _, err := ParseColor(w.Color); err != nil {
+			return fmt.Errorf("watermark color: %w", err)
+		}
+	}
+	return nil
+}
+
+// validateLaunchPreset проверяет браузер, канал, исполняемый файл и прокси пресета
+func validateLaunchPreset(p LaunchPreset) error {
+	switch p.Browser {
+	case "", "chromium", "firefox", "webkit":
+	default:
+		return fmt.Errorf("browser %q must be chromium, firefox or webkit", p.Browser)
+	}
+	if p.Channel != "" {
+		if p.Browser != "chromium" {
+			return fmt.Errorf("channel requires browser chromium")
+		}
+		if !slices.Contains(chromiumChannels, p.Channel) {
+			return fmt.Errorf("channel %q must be one of %s", p.Channel, strings.Join(chromiumChannels, ", "))
+		}
+	}
+	if p.ExecutablePath != "" {
+		if p.Browser == "" {
+			return fmt.Errorf("executable path requires browser")
+		}
+		if p.Channel != "" {
+			return fmt.Errorf("channel and executable path are mutually exclusive")
+		}
+		if _, err := os.Stat(p.ExecutablePath); err != nil {
+			return fmt.Errorf("executable path: %w", err)
+		}
+	}
+	if p.Proxy != nil {
+		if err := p.Proxy.Validate(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Validate проверяет адрес прокси-сервера, пустой прокси без остальных полей допустим.
+// Те же правила применяются к прокси из запроса
+func (p Proxy) Validate() error {
+	if p.Server == "" {
+		if p.Bypass != "" || p.Username != "" || p.Password != "" {
+			return fmt.Errorf("proxy bypass and credentials require proxy server")
+		}
+		return nil
+	}
+	server := p.Server
+	if !strings.Contains(server, "://") {
+		server = "http://" + server
+	}
+	u, err := url.Parse(server)
+	if err != nil || u.Host == "" || strings.Trim(u.Path, "/") != "" || u.RawQuery != "" {
+		return fmt.Errorf("proxy server %q must be host:port or scheme://host:port", p.Server)
+	}
+	if u.User != nil {
+		return fmt.Errorf("proxy credentials must be set in proxy username and password")
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return fmt.Errorf("proxy server %q scheme must be http, https or socks5", p.Server)
+	}
+	if p.Password != "" && p.Username == "" {
+		return fmt.Errorf("proxy password requires username")
+	}
+	return nil
 }
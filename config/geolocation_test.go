@@ -0,0 +1,47 @@
+package config
+
+import "testing"
+
+func TestParseGeolocation(t *testing.T) {
+	tests := []struct {
+		in   string
+		want Geolocation
+		ok   bool
+	}{
+		{"55.7558,37.6173", Geolocation{Latitude: 55.7558, Longitude: 37.6173}, true},
+		{" -33.9 , 151.2 , 25 ", Geolocation{Latitude: -33.9, Longitude: 151.2, Accuracy: 25}, true},
+		{"90,-180,0", Geolocation{Latitude: 90, Longitude: -180}, true},
+		{"", Geolocation{}, false},
+		{"55.7558", Geolocation{}, false},
+		{"1,2,3,4", Geolocation{}, false},
+		{"north,37", Geolocation{}, false},
+		{"91,0", Geolocation{}, false},
+		{"0,181", Geolocation{}, false},
+		{"0,0,-1", Geolocation{}, false},
+	}
+	for _, tt := range tests {
+		got, err := ParseGeolocation(tt.in)
+		if (err == nil) != tt.ok {
+			t.Errorf("ParseGeolocation(%q) error = %v, want ok %v", tt.in, err, tt.ok)
+			continue
+		}
+		if tt.ok && *got != tt.want {
+			t.Errorf("ParseGeolocation(%q) = %+v, want %+v", tt.in, *got, tt.want)
+		}
+	}
+}
+
+func TestValidateGeolocation(t *testing.T) {
+	cfg, err := Load("")
+	if err != nil {
+		t.Fatal(err)
+	}
+	cfg.Geolocation = "55.7558,37.6173,100"
+	if err := cfg.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+	cfg.Geolocation = "55.7558;37.6173"
+	if err := cfg.Validate(); err == nil {
+		t.Error("invalid geolocation is accepted")
+	}
+}
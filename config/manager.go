@@ -0,0 +1,179 @@
+package config
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"reflect"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	"time"
+)
+
+// watchInterval период проверки изменения файла конфигурации
+const watchInterval = 5 * time.Second
+
+// Manager хранит текущую конфигурацию и перечитывает её без перезапуска сервиса
+type Manager struct {
+	current atomic.Pointer[Config]
+
+	mu        sync.Mutex
+	listeners []func(old, cur *Config)
+}
+
+func NewManager(cfg *Config) *Manager {
+	m := &Manager{}
+	m.current.Store(cfg)
+	return m
+}
+
+// Get текущая конфигурация, возвращаемое значение нельзя изменять
+func (m *Manager) Get() *Config {
+	return m.current.Load()
+}
+
+// OnReload регистрирует обработчик, вызываемый после применения новой конфигурации
+func (m *Manager) OnReload(fn func(old, cur *Config)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.listeners = append(m.listeners, fn)
+}
+
+// Reload перечитывает файл и переменные окружения. Некорректная конфигурация
+// отклоняется, текущая при этом остаётся в силе. Применяются только настройки,
+// которые безопасно менять на лету; имена изменённых полей, требующих
+// перезапуска, возвращаются в ignored
+func (m *Manager) Reload() (ignored []string, err error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	old := m.Get()
+	next, err := Load(old.ConfigFile)
+	if err != nil {
+		return nil, err
+	}
+
+	cur := old.withReloadable(next)
+	ignored = changedFields(cur, next)
+	m.current.Store(cur)
+
+	for _, fn := range m.listeners {
+		fn(old, cur)
+	}
+
+	return ignored, nil
+}
+
+// Watch перечитывает конфигурацию по сигналу SIGHUP и при изменении файла.
+// Результат каждой попытки передаётся в report
+func (m *Manager) Watch(ctx context.Context, report func(ignored []string, err error)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(watchInterval)
+	defer ticker.Stop()
+
+	lastMod := modTime(m.Get().ConfigFile)
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		case <-ticker.C:
+			mod := modTime(m.Get().ConfigFile)
+			if mod.Equal(lastMod) {
+				continue
+			}
+			lastMod = mod
+		}
+		report(m.Reload())
+	}
+}
+
+// withReloadable копия конфигурации с настройками из next, которые безопасно менять на лету
+func (c *Config) withReloadable(next *Config) *Config {
+	cfg := *c
+	cfg.AccessToken = next.AccessToken
+	cfg.AccessTokenScopes = next.AccessTokenScopes
+	cfg.APIKeys = next.APIKeys
+	cfg.LogLevel = next.LogLevel
+	cfg.MaxWorkers = next.MaxWorkers
+	cfg.QueueTimeout = next.QueueTimeout
+	cfg.SelectionBorderColor = next.SelectionBorderColor
+	cfg.SelectionBorderWidth = next.SelectionBorderWidth
+	cfg.SelectionBorderStyle = next.SelectionBorderStyle
+	cfg.SelectionBorderOpacity = next.SelectionBorderOpacity
+	cfg.AnnotationColor = next.AnnotationColor
+	cfg.AnnotationTextColor = next.AnnotationTextColor
+	cfg.AnnotationFontSize = next.AnnotationFontSize
+	cfg.AnnotationStrokeWidth = next.AnnotationStrokeWidth
+	cfg.SpotlightOpacity = next.SpotlightOpacity
+	cfg.WatermarkText = next.WatermarkText
+	cfg.WatermarkImage = next.WatermarkImage
+	cfg.WatermarkImageData = next.WatermarkImageData
+	cfg.WatermarkPosition = next.WatermarkPosition
+	cfg.WatermarkOpacity = next.WatermarkOpacity
+	cfg.WatermarkTile = next.WatermarkTile
+	cfg.WatermarkMargin = next.WatermarkMargin
+	cfg.WatermarkColor = next.WatermarkColor
+	cfg.WatermarkFontSize = next.WatermarkFontSize
+	cfg.Type = next.Type
+	cfg.Timeout = next.Timeout
+	cfg.FullPage = next.FullPage
+	cfg.DocumentMode = next.DocumentMode
+	cfg.DocumentOrigin = next.DocumentOrigin
+	cfg.ColorScheme = next.ColorScheme
+	cfg.ReducedMotion = next.ReducedMotion
+	cfg.Locale = next.Locale
+	cfg.TimezoneID = next.TimezoneID
+	cfg.AcceptLanguage = next.AcceptLanguage
+	cfg.Media = next.Media
+	cfg.Geolocation = next.Geolocation
+	cfg.MarkupStylesheet = next.MarkupStylesheet
+	cfg.CodeTheme = next.CodeTheme
+	cfg.MaxViewportWidth = next.MaxViewportWidth
+	cfg.MaxViewportHeight = next.MaxViewportHeight
+	cfg.MaxFullPageHeight = next.MaxFullPageHeight
+	cfg.MaxTimeout = next.MaxTimeout
+	cfg.AllowedTypes = next.AllowedTypes
+	cfg.AllowedBrowsers = next.AllowedBrowsers
+	cfg.MaxBundleSize = next.MaxBundleSize
+	cfg.MaxBundleFiles = next.MaxBundleFiles
+	cfg.MaxActions = next.MaxActions
+	cfg.MaxScrollSteps = next.MaxScrollSteps
+	cfg.MaxAnimationDuration = next.MaxAnimationDuration
+	cfg.MaxFrameRate = next.MaxFrameRate
+	cfg.MaxAnimationSize = next.MaxAnimationSize
+	cfg.ProxyServer = next.ProxyServer
+	cfg.ProxyBypass = next.ProxyBypass
+	cfg.ProxyUsername = next.ProxyUsername
+	cfg.ProxyPassword = next.ProxyPassword
+	cfg.LaunchPresets = next.LaunchPresets
+	cfg.LaunchPreset = next.LaunchPreset
+	return &cfg
+}
+
+// changedFields имена полей, значения которых различаются
+func changedFields(a, b *Config) []string {
+	va, vb := reflect.ValueOf(a).Elem(), reflect.ValueOf(b).Elem()
+	var fields []string
+	for i := 0; i < va.NumField(); i++ {
+		if !reflect.DeepEqual(va.Field(i).Interface(), vb.Field(i).Interface()) {
+			fields = append(fields, va.Type().Field(i).Name)
+		}
+	}
+	return fields
+}
+
+func modTime(path string) time.Time {
+	if path == "" {
+		return time.Time{}
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return time.Time{}
+	}
+	return info.ModTime()
+}
@@ -0,0 +1,45 @@
+package config
+
+import "testing"
+
+func TestKeyWatermark(t *testing.T) {
+	on, off := true, false
+	tests := []struct {
+		name       string
+		serverTile bool
+		key        *APIKey
+		wantText   string // Текст итогового знака, пусто - без знака
+		wantTile   bool
+	}{
+		{name: "server watermark", serverTile: true, wantText: "server", wantTile: true},
+		{name: "key inherits tile", serverTile: true, key: &APIKey{Watermark: &Watermark{Text: "key"}}, wantText: "key", wantTile: true},
+		{name: "key disables tile", serverTile: true, key: &APIKey{Watermark: &Watermark{Text: "key", Tile: &off}}, wantText: "key"},
+		{name: "key enables tile", key: &APIKey{Watermark: &Watermark{Text: "key", Tile: &on}}, wantText: "key", wantTile: true},
+		{name: "key without watermark", serverTile: true, key: &APIKey{Watermark: &Watermark{}}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			cfg := &Config{WatermarkText: "server", WatermarkTile: tt.serverTile}
+			got := cfg.KeyWatermark(tt.key)
+			if tt.wantText == "" {
+				if got != nil {
+					t.Fatalf("KeyWatermark() = %+v, want nil", got)
+				}
+				return
+			}
+			if got == nil || got.Text != tt.wantText {
+				t.Fatalf("KeyWatermark() = %+v, want text %q", got, tt.wantText)
+			}
+			if tile := got.Tile != nil && *got.Tile; tile != tt.wantTile {
+				t.Errorf("tile = %v, want %v", tile, tt.wantTile)
+			}
+		})
+	}
+
+	// Оформление ключа не меняет общее
+	cfg := &Config{WatermarkText: "server", WatermarkTile: true}
+	cfg.KeyWatermark(&APIKey{Watermark: &Watermark{Text: "key", Tile: &off}})
+	if !cfg.WatermarkTile || !*cfg.KeyWatermark(nil).Tile {
+		t.Error("key watermark changed the server tile setting")
+	}
+}
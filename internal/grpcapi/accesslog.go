@@ -0,0 +1,154 @@
+package grpcapi
+
+import (
+	"context"
+	"net"
+	"screenshoter/internal/middleware"
+	"screenshoter/internal/service"
+	"screenshoter/pkg/logger"
+	"time"
+
+	"github.com/rs/zerolog"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+)
+
+// requestIDMetadata метаданные с идентификатором вызова, как X-Request-ID в HTTP API
+const requestIDMetadata = "x-request-id"
+
+type accessLogContextKey struct{}
+
+// accessLogFields поля журнала вызова, которые становятся известны ниже по цепочке: ключ и параметры рендера
+type accessLogFields struct {
+	apiKey  string
+	browser string
+	typ     string
+}
+
+// UnaryAccessLogInterceptor берет x-request-id из метаданных или создает новый, возвращает его в заголовке ответа,
+// добавляет в контекст логгер с request_id и пишет одну строку журнала на вызов.
+// Должен быть первым в цепочке, чтобы вызовы с паникой и без токена тоже попадали в журнал
+func UnaryAccessLogInterceptor(lgr *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		start := time.Now()
+		ctx, fields := withRequestID(ctx, lgr)
+
+		resp, err := handler(ctx, req)
+
+		size := 0
+		if msg, ok := resp.(proto.Message); ok && err == nil {
+			size = proto.Size(msg)
+		}
+		logCall(ctx, lgr, info.FullMethod, fields, err, start, size)
+		return resp, err
+	}
+}
+
+// StreamAccessLogInterceptor то же для потоковых вызовов, bytes - сумма отправленных сообщений
+func StreamAccessLogInterceptor(lgr *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		start := time.Now()
+		ctx, fields := withRequestID(ss.Context(), lgr)
+
+		stream := &countingStream{ServerStream: ss, ctx: ctx}
+		err := handler(srv, stream)
+
+		logCall(ctx, lgr, info.FullMethod, fields, err, start, stream.bytes)
+		return err
+	}
+}
+
+// withRequestID добавляет в контекст идентификатор вызова, логгер и поля журнала
+func withRequestID(ctx context.Context, lgr *logger.Logger) (context.Context, *accessLogFields) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	var id string
+	if values := md.Get(requestIDMetadata); len(values) > 0 {
+		id = values[0]
+	}
+	id = middleware.EnsureRequestID(id)
+	_ = grpc.SetHeader(ctx, metadata.Pairs(requestIDMetadata, id))
+	trace.SpanFromContext(ctx).SetAttributes(attribute.String("request.id", id))
+
+	ctx = logger.WithRequestID(ctx, id)
+	reqLogger := &logger.Logger{Logger: lgr.With().Str("request_id", id).Ctx(ctx).Logger()}
+	ctx = logger.NewContext(ctx, reqLogger)
+
+	fields := &accessLogFields{}
+	return context.WithValue(ctx, accessLogContextKey{}, fields), fields
+}
+
+// logCall пишет строку журнала с теми же полями, что и журнал HTTP-запросов
+func logCall(ctx context.Context, lgr *logger.Logger, method string, fields *accessLogFields, err error, start time.Time, size int) {
+	code := status.Code(err)
+	reqLogger := logger.FromContext(ctx, lgr)
+	var event *zerolog.Event
+	switch code {
+	case codes.Internal, codes.Unknown, codes.DataLoss, codes.Unavailable:
+		event = reqLogger.Warn()
+	default:
+		event = reqLogger.Info()
+	}
+
+	event = event.
+		Str("method", "GRPC").
+		Str("path", method).
+		Int("status", int(code)).
+		Str("grpc_code", code.String()).
+		Dur("duration", time.Since(start)).
+		Int("bytes", size)
+	if p, ok := peer.FromContext(ctx); ok {
+		host, _, splitErr := net.SplitHostPort(p.Addr.String())
+		if splitErr != nil {
+			host = p.Addr.String()
+		}
+		event = event.Str("client_ip", host)
+	}
+	if fields.apiKey != "" {
+		event = event.Str("api_key", fields.apiKey)
+	}
+	if fields.browser != "" {
+		event = event.Str("browser", fields.browser)
+	}
+	if fields.typ != "" {
+		event = event.Str("type", fields.typ)
+	}
+	event.Msg("request")
+}
+
+// setLogAPIKey передает имя ключа в журнал вызова
+func setLogAPIKey(ctx context.Context, name string) {
+	if fields, ok := ctx.Value(accessLogContextKey{}).(*accessLogFields); ok {
+		fields.apiKey = name
+	}
+}
+
+// setLogRender передает браузер и формат в журнал вызова
+func setLogRender(ctx context.Context, opts service.ScreenshotOptions) {
+	if fields, ok := ctx.Value(accessLogContextKey{}).(*accessLogFields); ok {
+		fields.browser, fields.typ = string(opts.Browser), opts.OutputType()
+	}
+}
+
+// countingStream подменяет контекст потока и считает размер отправленных сообщений
+type countingStream struct {
+	grpc.ServerStream
+	ctx   context.Context
+	bytes int
+}
+
+func (s *countingStream) Context() context.Context {
+	return s.ctx
+}
+
+func (s *countingStream) SendMsg(m any) error {
+	if msg, ok := m.(proto.Message); ok {
+		s.bytes += proto.Size(msg)
+	}
+	return s.ServerStream.SendMsg(m)
+}
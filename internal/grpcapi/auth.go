@@ -0,0 +1,73 @@
+package grpcapi
+
+import (
+	"context"
+	"screenshoter/config"
+	"strings"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryAuthInterceptor проверяет bearer-токен так же, как HTTP API
+func UnaryAuthInterceptor(cfg *config.Manager) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, _ *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		ctx, err := authorize(ctx, cfg)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+// StreamAuthInterceptor проверяет bearer-токен для потоковых вызовов
+func StreamAuthInterceptor(cfg *config.Manager) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, _ *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := authorize(ss.Context(), cfg)
+		if err != nil {
+			return err
+		}
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+type apiKeyContextKey struct{}
+
+// contextStream подменяет контекст потока: ключ доступа, hub Sentry, логгер вызова
+type contextStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+func (s *contextStream) Context() context.Context {
+	return s.ctx
+}
+
+// APIKey ключ доступа, с которым выполнен вызов
+func APIKey(ctx context.Context) *config.APIKey {
+	key, _ := ctx.Value(apiKeyContextKey{}).(*config.APIKey)
+	return key
+}
+
+// authorize проверяет токен и добавляет найденный ключ в контекст
+func authorize(ctx context.Context, cfg *config.Manager) (context.Context, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("authorization")
+	if len(values) == 0 {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata is required")
+	}
+
+	token, ok := strings.CutPrefix(values[0], "Bearer ")
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "authorization metadata format must be Bearer {token}")
+	}
+	key, ok := cfg.Get().LookupKey(token)
+	if !ok {
+		return nil, status.Error(codes.Unauthenticated, "invalid token")
+	}
+
+	setLogAPIKey(ctx, key.Name)
+	return context.WithValue(ctx, apiKeyContextKey{}, key), nil
+}
@@ -0,0 +1,136 @@
+package grpcapi
+
+import (
+	"context"
+	"crypto/rand"
+	"errors"
+	"fmt"
+	pb "screenshoter/pkg/api/screenshoter/v1"
+	"sync"
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+// jobTTL время хранения результата асинхронной задачи
+const jobTTL = 10 * time.Minute
+
+// jobsPruneInterval период удаления устаревших задач
+const jobsPruneInterval = time.Minute
+
+// maxJobs максимальное число хранимых задач: результаты держатся в памяти до истечения jobTTL
+const maxJobs = 1000
+
+var errTooManyJobs = errors.New("too many async jobs, try again later")
+
+type job struct {
+	owner       string // Имя ключа, создавшего задачу
+	status      pb.JobStatus
+	err         string
+	contentType string
+	image       []byte
+	createdAt   time.Time
+	finishedAt  time.Time
+}
+
+// jobStore хранит асинхронные задачи в памяти
+type jobStore struct {
+	mu   sync.Mutex
+	jobs map[string]*job
+}
+
+func newJobStore() *jobStore {
+	return &jobStore{jobs: make(map[string]*job)}
+}
+
+// create регистрирует новую задачу ключа owner и удаляет устаревшие,
+// при maxJobs хранимых задач возвращает errTooManyJobs
+func (s *jobStore) create(owner string) (string, error) {
+	randBytes := make([]byte, 16)
+	if _, err := rand.Read(randBytes); err != nil {
+		return "", fmt.Errorf("failed to generate job id: %w", err)
+	}
+	id := fmt.Sprintf("%x", randBytes)
+
+	s.prune()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if len(s.jobs) >= maxJobs {
+		return "", errTooManyJobs
+	}
+	s.jobs[id] = &job{owner: owner, status: pb.JobStatus_JOB_STATUS_RUNNING, createdAt: time.Now()}
+
+	return id, nil
+}
+
+// prune удаляет задачи, завершенные раньше jobTTL
+func (s *jobStore) prune() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := time.Now()
+	for jobID, j := range s.jobs {
+		if !j.finishedAt.IsZero() && now.Sub(j.finishedAt) > jobTTL {
+			delete(s.jobs, jobID)
+		}
+	}
+}
+
+// watch удаляет устаревшие задачи по таймеру, даже если новых задач нет
+func (s *jobStore) watch(ctx context.Context) {
+	ticker := time.NewTicker(jobsPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			s.prune()
+		}
+	}
+}
+
+// finish сохраняет результат задачи
+func (s *jobStore) finish(id string, image []byte, contentType string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok {
+		return
+	}
+	j.finishedAt = time.Now()
+	if err != nil {
+		j.status = pb.JobStatus_JOB_STATUS_FAILED
+		j.err = err.Error()
+		return
+	}
+	j.status = pb.JobStatus_JOB_STATUS_DONE
+	j.image = image
+	j.contentType = contentType
+}
+
+// get состояние задачи; задача другого ключа не находится, чтобы не раскрывать ее существование
+func (s *jobStore) get(id, owner string) (*pb.Job, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	j, ok := s.jobs[id]
+	if !ok || j.owner != owner {
+		return nil, false
+	}
+	res := &pb.Job{
+		Id:          id,
+		Status:      j.status,
+		Error:       j.err,
+		ContentType: j.contentType,
+		Image:       j.image,
+		CreatedAt:   timestamppb.New(j.createdAt),
+	}
+	if !j.finishedAt.IsZero() {
+		res.FinishedAt = timestamppb.New(j.finishedAt)
+	}
+	return res, true
+}
@@ -0,0 +1,50 @@
+package grpcapi
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestJobStoreLimit(t *testing.T) {
+	s := newJobStore()
+	var first string
+	for i := 0; i < maxJobs; i++ {
+		id, err := s.create("key")
+		if err != nil {
+			t.Fatalf("job %d: %v", i, err)
+		}
+		if first == "" {
+			first = id
+		}
+	}
+	if _, err := s.create("key"); !errors.Is(err, errTooManyJobs) {
+		t.Fatalf("create over the limit = %v, want errTooManyJobs", err)
+	}
+
+	// Устаревшая задача освобождает место
+	s.finish(first, []byte("png"), "image/png", nil)
+	s.jobs[first].finishedAt = time.Now().Add(-jobTTL - time.Second)
+	if _, err := s.create("key"); err != nil {
+		t.Fatalf("create after prune: %v", err)
+	}
+	if _, ok := s.get(first, "key"); ok {
+		t.Error("expired job is kept")
+	}
+}
+
+func TestJobStoreOwner(t *testing.T) {
+	s := newJobStore()
+	id, err := s.create("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	s.finish(id, []byte("png"), "image/png", nil)
+	if _, ok := s.get(id, "b"); ok {
+		t.Error("job is visible to another key")
+	}
+	j, ok := s.get(id, "a")
+	if !ok || string(j.Image) != "png" || j.FinishedAt == nil {
+		t.Errorf("get = %+v, %v", j, ok)
+	}
+}
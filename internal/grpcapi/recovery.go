@@ -0,0 +1,58 @@
+package grpcapi
+
+import (
+	"context"
+	"screenshoter/internal/metrics"
+	"screenshoter/pkg/logger"
+
+	"github.com/getsentry/sentry-go"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// UnaryRecoveryInterceptor создает hub Sentry для вызова и перехватывает панику обработчика:
+// пишет ошибку со стеком в лог (и Sentry) и возвращает codes.Internal.
+// Должен идти сразу после журнала вызовов, чтобы остальные перехватчики видели hub
+func UnaryRecoveryInterceptor(lgr *logger.Logger) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (resp any, err error) {
+		ctx = withHub(ctx, lgr, info.FullMethod)
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = recovered(ctx, lgr, logger.NewPanicError(rec), info.FullMethod)
+			}
+		}()
+		return handler(ctx, req)
+	}
+}
+
+// StreamRecoveryInterceptor перехватывает панику потоковых вызовов
+func StreamRecoveryInterceptor(lgr *logger.Logger) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) (err error) {
+		ctx := withHub(ss.Context(), lgr, info.FullMethod)
+		defer func() {
+			if rec := recover(); rec != nil {
+				err = recovered(ctx, lgr, logger.NewPanicError(rec), info.FullMethod)
+			}
+		}()
+		return handler(srv, &contextStream{ServerStream: ss, ctx: ctx})
+	}
+}
+
+// withHub добавляет в контекст отдельный hub Sentry с методом и идентификатором вызова
+// и привязывает к нему логгер вызова
+func withHub(ctx context.Context, lgr *logger.Logger, method string) context.Context {
+	hub := sentry.CurrentHub().Clone()
+	hub.Scope().SetTag("grpc.method", method)
+	if id := logger.RequestID(ctx); id != "" {
+		hub.Scope().SetTag("request_id", id)
+	}
+	return logger.Rebind(sentry.SetHubOnContext(ctx, hub), lgr)
+}
+
+// recovered пишет панику в лог и Sentry и возвращает ошибку вызова
+func recovered(ctx context.Context, lgr *logger.Logger, panicErr *logger.PanicError, method string) error {
+	logger.FromContext(ctx, lgr).Exception(panicErr).Str("grpc_method", method).Msg("Panic recovered")
+	metrics.TotalRequests.WithLabelValues("500").Inc()
+	return status.Error(codes.Internal, "internal server error")
+}
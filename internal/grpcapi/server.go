@@ -0,0 +1,405 @@
+package grpcapi
+
+import (
+	"context"
+	"errors"
+	"screenshoter/config"
+	"screenshoter/internal/metrics"
+	"screenshoter/internal/options"
+	"screenshoter/internal/service"
+	"screenshoter/internal/workerpool"
+	pb "screenshoter/pkg/api/screenshoter/v1"
+	"screenshoter/pkg/logger"
+	"strconv"
+	"time"
+
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// chunkSize размер части изображения в CaptureStream
+const chunkSize = 64 << 10 // 64Kb
+
+// Server реализация gRPC API поверх того же сервиса и пула воркеров, что и HTTP API
+type Server struct {
+	pb.UnimplementedScreenshoterServer
+
+	service *service.Service
+	config  *config.Manager
+	pool    *workerpool.Pool
+	lgr     *logger.Logger
+	jobs    *jobStore
+}
+
+func NewServer(service *service.Service, cfg *config.Manager, pool *workerpool.Pool, lgr *logger.Logger) *Server {
+	return &Server{
+		service: service,
+		config:  cfg,
+		pool:    pool,
+		lgr:     lgr,
+		jobs:    newJobStore(),
+	}
+}
+
+// Capture делает скриншот, при async = true возвращает идентификатор задачи
+func (s *Server) Capture(ctx context.Context, req *pb.CaptureRequest) (*pb.CaptureResponse, error) {
+	startTime := time.Now()
+	resp, opts, err := s.capture(ctx, req, startTime)
+	// Асинхронная задача записывается в метрики, когда завершится
+	if err != nil || !req.GetAsync() {
+		observeCall(opts, startTime, err)
+	}
+	return resp, err
+}
+
+func (s *Server) capture(ctx context.Context, req *pb.CaptureRequest, startTime time.Time) (*pb.CaptureResponse, service.ScreenshotOptions, error) {
+	if req.GetHtml() == "" {
+		return nil, service.ScreenshotOptions{}, status.Error(codes.InvalidArgument, "html content is required")
+	}
+
+	opts, err := s.screenshotOptions(ctx, req.GetOptions())
+	if err != nil {
+		return nil, opts, err
+	}
+
+	release, err := s.acquireWorker(ctx)
+	if err != nil {
+		return nil, opts, err
+	}
+
+	if req.GetAsync() {
+		id, err := s.jobs.create(APIKey(ctx).Name)
+		if errors.Is(err, errTooManyJobs) {
+			release()
+			return nil, opts, status.Error(codes.ResourceExhausted, err.Error())
+		}
+		if err != nil {
+			release()
+			return nil, opts, status.Error(codes.Internal, err.Error())
+		}
+		// Задача переживает вызов: контекст без отмены сохраняет hub Sentry и логгер запроса
+		jobCtx := context.WithoutCancel(ctx)
+		go func() {
+			defer release()
+			defer func() {
+				if rec := recover(); rec != nil {
+					err := logger.NewPanicError(rec)
+					logger.FromContext(jobCtx, s.lgr).Exception(err).Str("job_id", id).Msg("Panic recovered in async capture")
+					s.jobs.finish(id, nil, "", errors.New("internal server error"))
+				}
+			}()
+			res, err := s.render(jobCtx, req.GetHtml(), opts)
+			observeCall(opts, startTime, err)
+			if err != nil {
+				logger.FromContext(jobCtx, s.lgr).Warn().Str("job_id", id).Msgf("async capture failed: %v", err)
+				res = &service.Result{}
+			}
+			s.jobs.finish(id, res.Image, res.ContentType, err)
+		}()
+		return &pb.CaptureResponse{JobId: id}, opts, nil
+	}
+
+	defer release()
+	res, err := s.render(ctx, req.GetHtml(), opts)
+	if err != nil {
+		return nil, opts, err
+	}
+
+	return &pb.CaptureResponse{ContentType: res.ContentType, Image: res.Image}, opts, nil
+}
+
+// CaptureStream делает скриншот и отдаёт его частями по chunkSize
+func (s *Server) CaptureStream(req *pb.CaptureRequest, stream pb.Screenshoter_CaptureStreamServer) error {
+	startTime := time.Now()
+	opts, err := s.captureStream(req, stream)
+	observeCall(opts, startTime, err)
+	return err
+}
+
+func (s *Server) captureStream(req *pb.CaptureRequest, stream pb.Screenshoter_CaptureStreamServer) (service.ScreenshotOptions, error) {
+	if req.GetHtml() == "" {
+		return service.ScreenshotOptions{}, status.Error(codes.InvalidArgument, "html content is required")
+	}
+	if req.GetAsync() {
+		return service.ScreenshotOptions{}, status.Error(codes.InvalidArgument, "async is not supported for CaptureStream")
+	}
+
+	opts, err := s.screenshotOptions(stream.Context(), req.GetOptions())
+	if err != nil {
+		return opts, err
+	}
+
+	release, err := s.acquireWorker(stream.Context())
+	if err != nil {
+		return opts, err
+	}
+	defer release()
+
+	res, err := s.render(stream.Context(), req.GetHtml(), opts)
+	if err != nil {
+		return opts, err
+	}
+	bytes, contentType := res.Image, res.ContentType
+
+	for offset := 0; offset < len(bytes) || offset == 0; offset += chunkSize {
+		chunk := &pb.CaptureChunk{Data: bytes[offset:min(offset+chunkSize, len(bytes))]}
+		if offset == 0 {
+			chunk.ContentType = contentType
+			chunk.TotalSize = int64(len(bytes))
+		}
+		if err := stream.Send(chunk); err != nil {
+			return opts, err
+		}
+	}
+
+	return opts, nil
+}
+
+// observeCall записывает число и длительность вызова со статусом в терминах HTTP, как у HTTP API;
+// если параметры не разобраны, браузер и формат - unknown
+func observeCall(opts service.ScreenshotOptions, startTime time.Time, err error) {
+	browser, outputType := "unknown", "unknown"
+	if opts.Browser != "" {
+		browser, outputType = string(opts.Browser), opts.OutputType()
+	}
+	code := httpStatus(err)
+	metrics.TotalRequests.WithLabelValues(code).Inc()
+	metrics.RequestDuration.
+		WithLabelValues(browser, outputType, code).
+		Observe(time.Since(startTime).Seconds())
+}
+
+// httpStatus код HTTP API, соответствующий ошибке вызова
+func httpStatus(err error) string {
+	switch status.Code(err) {
+	case codes.OK:
+		return "200"
+	case codes.InvalidArgument:
+		return "400"
+	case codes.Unauthenticated:
+		return "401"
+	case codes.PermissionDenied:
+		return "403"
+	case codes.NotFound:
+		return "404"
+	case codes.FailedPrecondition:
+		return "422"
+	case codes.ResourceExhausted:
+		return "429"
+	case codes.Canceled:
+		return "499"
+	case codes.DeadlineExceeded:
+		return "504"
+	default:
+		return "500"
+	}
+}
+
+// GetJob возвращает состояние асинхронной задачи
+func (s *Server) GetJob(ctx context.Context, req *pb.GetJobRequest) (*pb.Job, error) {
+	j, ok := s.jobs.get(req.GetJobId(), APIKey(ctx).Name)
+	if !ok {
+		return nil, status.Error(codes.NotFound, "job not found")
+	}
+	return j, nil
+}
+
+// ExpireJobs удаляет устаревшие результаты асинхронных задач, пока не отменен ctx
+func (s *Server) ExpireJobs(ctx context.Context) {
+	s.jobs.watch(ctx)
+}
+
+// acquireWorker занимает слот в общем пуле воркеров
+func (s *Server) acquireWorker(ctx context.Context) (func(), error) {
+	if ctx.Err() != nil {
+		return nil, status.Error(codes.Canceled, "request cancelled by client")
+	}
+
+	// Ждем свободный воркер не дольше queue_timeout и дедлайна вызова
+	waitCtx, cancel := context.WithTimeout(ctx, time.Duration(s.config.Get().QueueTimeout)*time.Millisecond)
+	defer cancel()
+	waitStart := time.Now()
+	release, err := s.pool.Acquire(waitCtx)
+	metrics.QueueWait.Observe(time.Since(waitStart).Seconds())
+	if err != nil {
+		if ctx.Err() != nil {
+			return nil, status.FromContextError(ctx.Err()).Err()
+		}
+		return nil, status.Error(codes.ResourceExhausted, "server busy, try again later")
+	}
+	return release, nil
+}
+
+// render создает скриншот с ограничением по времени
+func (s *Server) render(ctx context.Context, html string, opts service.ScreenshotOptions) (*service.Result, error) {
+	ctx, cancel := context.WithTimeout(ctx, service.RenderTimeout(opts))
+	defer cancel()
+
+	// Канал для результата
+	resultChan := make(chan struct {
+		res *service.Result
+		err error
+	}, 1)
+
+	// Паника в горутине рендера не доходит до перехватчика вызова, поэтому перехватывается здесь
+	go func() {
+		defer func() {
+			if rec := recover(); rec != nil {
+				resultChan <- struct {
+					res *service.Result
+					err error
+				}{nil, logger.NewPanicError(rec)}
+			}
+		}()
+		res, err := s.service.Screenshot.Make(ctx, html, opts)
+		resultChan <- struct {
+			res *service.Result
+			err error
+		}{res, err}
+	}()
+
+	select {
+	case result := <-resultChan:
+		var limitErr *service.LimitError
+		if errors.As(result.err, &limitErr) {
+			return nil, status.Error(codes.InvalidArgument, limitErr.Error())
+		}
+		var actionErr *service.ActionError
+		if errors.As(result.err, &actionErr) {
+			return nil, status.Error(codes.FailedPrecondition, actionErr.Error())
+		}
+		var selectionErr *service.SelectionError
+		if errors.As(result.err, &selectionErr) {
+			return nil, status.Error(codes.FailedPrecondition, selectionErr.Error())
+		}
+		var annotationErr *service.AnnotationError
+		if errors.As(result.err, &annotationErr) {
+			return nil, status.Error(codes.FailedPrecondition, annotationErr.Error())
+		}
+		var pageErr *service.PageError
+		if errors.As(result.err, &pageErr) {
+			return nil, status.Error(codes.FailedPrecondition, pageErr.Error())
+		}
+		var panicErr *logger.PanicError
+		if errors.As(result.err, &panicErr) {
+			logger.FromContext(ctx, s.lgr).Exception(panicErr).Msg("Panic recovered in render")
+			return nil, status.Error(codes.Internal, "internal server error")
+		}
+		if result.err != nil {
+			return nil, status.Error(codes.Internal, result.err.Error())
+		}
+		return result.res, nil
+	case <-ctx.Done():
+		if errors.Is(ctx.Err(), context.DeadlineExceeded) {
+			return nil, status.Error(codes.DeadlineExceeded, "screenshot generation timeout")
+		}
+		return nil, status.Error(codes.Canceled, "request cancelled by client")
+	}
+}
+
+// screenshotOptions переводит параметры запроса в service.ScreenshotOptions,
+// незаданные значения берутся такими же, как в HTTP API, и проверяет ограничения сервера.
+// Водяной знак ключа накладывается всегда: заменить или отключить его через gRPC нельзя
+func (s *Server) screenshotOptions(ctx context.Context, o *pb.ScreenshotOptions) (service.ScreenshotOptions, error) {
+	cfg := s.config.Get()
+	opts := service.ScreenshotOptions{
+		Browser:        service.BrowserChromium,
+		Type:           cfg.Type,
+		FullPage:       cfg.FullPage,
+		OmitBackground: o.GetOmitBackground(),
+		Timeout:        float64(cfg.Timeout),
+		SelectionStyle: &service.SelectionStyle{
+			BorderColor: cfg.SelectionBorderColor,
+			BorderWidth: cfg.SelectionBorderWidth,
+			BorderStyle: cfg.SelectionBorderStyle,
+			Opacity:     cfg.SelectionBorderOpacity,
+		},
+		ScrollX:        int(o.GetScrollX()),
+		ScrollY:        int(o.GetScrollY()),
+		DocumentMode:   cfg.DocumentMode,
+		DocumentOrigin: cfg.DocumentOrigin,
+		Emulation:      options.Emulation(cfg),
+	}
+
+	switch o.GetBrowser() {
+	case pb.Browser_BROWSER_FIREFOX:
+		opts.Browser = service.BrowserFirefox
+	case pb.Browser_BROWSER_WEBKIT:
+		opts.Browser = service.BrowserWebkit
+	}
+	if o.GetType() != "" {
+		opts.Type = o.GetType()
+	}
+	setLogRender(ctx, opts)
+	if o != nil && o.Quality != nil {
+		quality := int(o.GetQuality())
+		opts.Quality = &quality
+	}
+	if o != nil && o.FullPage != nil {
+		opts.FullPage = o.GetFullPage()
+	}
+	if o != nil && o.Timeout != nil {
+		opts.Timeout = o.GetTimeout()
+	}
+	if v := o.GetViewport(); v != nil {
+		opts.Viewport = &struct {
+			Width  int `json:"width"`
+			Height int `json:"height"`
+		}{Width: int(v.GetWidth()), Height: int(v.GetHeight())}
+	}
+	for _, sel := range o.GetSelections() {
+		opts.Selections = append(opts.Selections, service.SelectionArea{
+			X:      int(sel.GetX()),
+			Y:      int(sel.GetY()),
+			Width:  int(sel.GetWidth()),
+			Height: int(sel.GetHeight()),
+		})
+	}
+	if st := o.GetSelectionStyle(); st != nil {
+		if st.GetBorderColor() != "" {
+			opts.SelectionStyle.BorderColor = st.GetBorderColor()
+		}
+		if st.GetBorderWidth() > 0 {
+			opts.SelectionStyle.BorderWidth = int(st.GetBorderWidth())
+		}
+		if st.GetBorderStyle() != "" {
+			opts.SelectionStyle.BorderStyle = st.GetBorderStyle()
+		}
+		if st.GetOpacity() > 0 {
+			opts.SelectionStyle.Opacity = st.GetOpacity()
+		}
+	}
+
+	if err := service.ValidateSelections(opts.Selections); err != nil {
+		return opts, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err := service.ValidateAnimation(opts); err != nil {
+		return opts, status.Error(codes.InvalidArgument, err.Error())
+	}
+	// Знак ключа отключается метаданными x-no-watermark: true, как no_watermark в HTTP API
+	opts.Watermark = options.Watermark(cfg.KeyWatermark(APIKey(ctx)))
+	if noWatermark(ctx) {
+		if opts.Watermark != nil && !APIKey(ctx).HasScope(config.ScopeWatermark) {
+			return opts, status.Error(codes.PermissionDenied, "disabling the watermark requires an api key with watermark scope")
+		}
+		opts.Watermark = nil
+	}
+	opts.Proxy = options.Proxy(cfg.KeyProxy(APIKey(ctx)))
+	if err := options.Limits(cfg).Apply(&opts); err != nil {
+		return opts, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return opts, nil
+}
+
+// noWatermark запрошено ли метаданными x-no-watermark отключение водяного знака ключа
+func noWatermark(ctx context.Context) bool {
+	md, _ := metadata.FromIncomingContext(ctx)
+	values := md.Get("x-no-watermark")
+	if len(values) == 0 {
+		return false
+	}
+	disable, _ := strconv.ParseBool(values[0])
+	return disable
+}
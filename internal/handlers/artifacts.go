@@ -0,0 +1,62 @@
+package handlers
+
+import (
+	"archive/zip"
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"screenshoter/internal/middleware"
+	"screenshoter/internal/service"
+	"screenshoter/pkg/logger"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// archiveFile файл в ZIP-архиве ответа
+type archiveFile struct {
+	name string
+	data []byte
+}
+
+// artifactsResponse отдает ZIP с изображением, событиями страницы, HAR и трассой playwright
+func (h *Handler) artifactsResponse(ctx *gin.Context, res *service.Result) {
+	// image/png, image/jpeg, image/gif или video/webm
+	_, ext, _ := strings.Cut(res.ContentType, "/")
+
+	diagnostics, _ := json.MarshalIndent(res.Diagnostics, "", "  ")
+	archive, err := buildArchive(
+		archiveFile{"screenshot." + ext, res.Image},
+		archiveFile{"diagnostics.json", diagnostics},
+		archiveFile{"network.har", res.Artifacts.HAR},
+		archiveFile{"trace.zip", res.Artifacts.Trace},
+	)
+	if err != nil {
+		logger.FromContext(ctx.Request.Context(), h.lgr).Exception(err).Msg("Failed to build artifacts archive")
+		newErrorResponse(ctx, http.StatusInternalServerError, "failed to build artifacts archive")
+		return
+	}
+
+	ctx.Header("Content-Disposition", fmt.Sprintf(`attachment; filename="render-%s.zip"`, middleware.RequestID(ctx)))
+	ctx.Data(http.StatusOK, "application/zip", archive)
+}
+
+// buildArchive упаковывает файлы в ZIP
+func buildArchive(files ...archiveFile) ([]byte, error) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for _, f := range files {
+		w, err := zw.Create(f.name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := w.Write(f.data); err != nil {
+			return nil, err
+		}
+	}
+	if err := zw.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,91 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"screenshoter/internal/service"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+)
+
+// bundle собирает страницу с ресурсами из запроса: ZIP-архив в поле bundle
+// или html вместе с файлами assets. Если вложений нет, возвращает nil
+func (h *Handler) bundle(ctx *gin.Context, html string) (*service.Bundle, error) {
+	form, err := ctx.MultipartForm()
+	if errors.Is(err, http.ErrNotMultipart) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("invalid multipart form: %w", err)
+	}
+
+	limits := h.bundleLimits()
+
+	if files := form.File["bundle"]; len(files) > 0 {
+		if html != "" {
+			return nil, fmt.Errorf("html and bundle cannot be used together")
+		}
+		header := files[0]
+		if limits.MaxSize > 0 && header.Size > limits.MaxSize {
+			return nil, &service.LimitError{Limit: "max_bundle_size", Value: header.Size, Max: limits.MaxSize}
+		}
+		file, err := header.Open()
+		if err != nil {
+			return nil, err
+		}
+		defer file.Close()
+		data, err := io.ReadAll(file)
+		if err != nil {
+			return nil, err
+		}
+		return service.ExtractZip(data, ctx.PostForm("entry"), limits)
+	}
+
+	assets := form.File["assets"]
+	if len(assets) == 0 {
+		return nil, nil
+	}
+	if html == "" {
+		return nil, fmt.Errorf("html is required with assets")
+	}
+
+	b, err := service.NewBundle(limits)
+	if err != nil {
+		return nil, err
+	}
+	if err := b.Add(service.BundleFile{Name: service.DefaultBundleEntry, Data: strings.NewReader(html)}); err != nil {
+		b.Close()
+		return nil, err
+	}
+	for _, header := range assets {
+		file, err := header.Open()
+		if err != nil {
+			b.Close()
+			return nil, err
+		}
+		err = b.Add(service.BundleFile{Name: header.Filename, Data: file})
+		file.Close()
+		if err != nil {
+			b.Close()
+			return nil, err
+		}
+	}
+	if err := b.SetEntry(service.DefaultBundleEntry); err != nil {
+		b.Close()
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// bundleLimits ограничения набора файлов из конфигурации
+func (h *Handler) bundleLimits() service.BundleLimits {
+	cfg := h.cfg()
+	return service.BundleLimits{
+		MaxSize:  cfg.MaxBundleSize,
+		MaxFiles: cfg.MaxBundleFiles,
+	}
+}
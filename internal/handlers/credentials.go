@@ -0,0 +1,35 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"screenshoter/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// credentials заголовки, куки, basic-аутентификация и состояние хранилища из параметров запроса.
+// Значения секретные, поэтому ошибки разбора их не содержат
+func credentials(ctx *gin.Context) (service.Credentials, error) {
+	c := service.Credentials{
+		UserAgent:    ctx.PostForm("user_agent"),
+		StorageState: service.Secret(ctx.PostForm("storage_state")),
+	}
+	if v := ctx.PostForm("extra_http_headers"); v != "" {
+		if err := json.Unmarshal([]byte(v), &c.ExtraHTTPHeaders); err != nil {
+			return c, fmt.Errorf("extra_http_headers must be a JSON object of strings")
+		}
+	}
+	if v := ctx.PostForm("cookies"); v != "" {
+		if err := json.Unmarshal([]byte(v), &c.Cookies); err != nil {
+			return c, fmt.Errorf("cookies must be a JSON array of cookies")
+		}
+	}
+	if username := ctx.PostForm("http_username"); username != "" {
+		c.HTTPCredentials = &service.HTTPCredentials{
+			Username: username,
+			Password: service.Secret(ctx.PostForm("http_password")),
+		}
+	}
+	return c, c.Validate()
+}
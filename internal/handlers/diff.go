@@ -0,0 +1,146 @@
+package handlers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"screenshoter/internal/metrics"
+	"screenshoter/internal/service"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// maxDiffImageSize максимальный размер загружаемого изображения для сравнения
+const maxDiffImageSize = 20 << 20 // 20Mb
+
+type diffResponse struct {
+	*service.DiffResult
+	DiffImage string `json:"diff_image"` // PNG в base64
+}
+
+// Diff сравнивает эталонное изображение с загруженным изображением
+// или со скриншотом переданного html
+func (h *Handler) Diff(ctx *gin.Context) {
+	startTime := time.Now()
+
+	// Параметры рендера, если сравнивается скриншот html; при сравнении загруженных изображений
+	// браузер и формат в метриках - unknown
+	var screenshotOpts service.ScreenshotOptions
+	defer func() {
+		observeRequest(ctx, screenshotOpts, startTime)
+	}()
+
+	// Параметры сравнения проверяются до чтения загруженных изображений
+	opts, err := h.diffOptions(ctx)
+	if err != nil {
+		optionsErrorResponse(ctx, err)
+		return
+	}
+
+	baseline, err := readFormFile(ctx, "baseline")
+	if err != nil {
+		optionsErrorResponse(ctx, fmt.Errorf("baseline image is required: %w", err))
+		return
+	}
+
+	html, err := h.document(ctx)
+	if err != nil {
+		optionsErrorResponse(ctx, err)
+		return
+	}
+
+	var actual []byte
+	if html != "" {
+		if screenshotOpts, err = h.formOptions(ctx); err != nil {
+			scopeErrorResponse(ctx, err)
+			return
+		}
+		setRenderInfo(ctx, screenshotOpts)
+	} else {
+		actual, err = readFormFile(ctx, "image")
+		if err != nil {
+			optionsErrorResponse(ctx, fmt.Errorf("either image or html, markdown or text is required: %w", err))
+			return
+		}
+	}
+
+	// Сравнение больших изображений нагружает сервер не меньше рендера, поэтому тоже занимает воркер
+	release, ok := h.acquireWorker(ctx)
+	if !ok {
+		return
+	}
+	defer release()
+
+	if html != "" {
+		res, ok := h.render(ctx, html, screenshotOpts)
+		if !ok {
+			return
+		}
+		actual = res.Image
+	}
+
+	result, err := h.service.Diff.Compare(baseline, actual, opts)
+	if err != nil {
+		optionsErrorResponse(ctx, err)
+		return
+	}
+
+	metrics.TotalRequests.WithLabelValues("200").Inc()
+	ctx.JSON(http.StatusOK, diffResponse{
+		DiffResult: result,
+		DiffImage:  base64.StdEncoding.EncodeToString(result.Image),
+	})
+}
+
+// diffOptions собирает параметры сравнения из запроса
+func (h *Handler) diffOptions(ctx *gin.Context) (service.DiffOptions, error) {
+	opts := service.DiffOptions{
+		Threshold:          0.1,
+		IgnoreAntialiasing: true,
+		AATolerance:        2,
+		SelectionStyle:     h.selectionStyle(),
+		MaxWidth:           h.cfg().MaxViewportWidth,
+		MaxHeight:          h.cfg().MaxFullPageHeight,
+	}
+
+	var err error
+	if v := ctx.PostForm("threshold"); v != "" {
+		if opts.Threshold, err = strconv.ParseFloat(v, 64); err != nil {
+			return opts, fmt.Errorf("invalid threshold value")
+		}
+	}
+	if v := ctx.PostForm("ignore_aa"); v != "" {
+		if opts.IgnoreAntialiasing, err = strconv.ParseBool(v); err != nil {
+			return opts, fmt.Errorf("invalid ignore_aa value")
+		}
+	}
+	if v := ctx.PostForm("aa_tolerance"); v != "" {
+		if opts.AATolerance, err = strconv.Atoi(v); err != nil {
+			return opts, fmt.Errorf("invalid aa_tolerance value")
+		}
+	}
+
+	return opts, opts.Validate()
+}
+
+// readFormFile читает содержимое загруженного файла с ограничением размера
+func readFormFile(ctx *gin.Context, name string) ([]byte, error) {
+	header, err := ctx.FormFile(name)
+	if err != nil {
+		return nil, err
+	}
+	if header.Size > maxDiffImageSize {
+		return nil, fmt.Errorf("file %s exceeds %d bytes", name, maxDiffImageSize)
+	}
+
+	file, err := header.Open()
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	return io.ReadAll(file)
+}
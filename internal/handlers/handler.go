@@ -5,34 +5,51 @@ import (
 	"screenshoter/config"
 	"screenshoter/internal/middleware"
 	"screenshoter/internal/service"
+	"screenshoter/internal/workerpool"
+	"screenshoter/pkg/logger"
 
 	gin "github.com/gin-gonic/gin"
 )
 
 type Handler struct {
-	service    *service.Service
-	cfg        *config.Config
-	workerPool chan struct{}
+	service *service.Service
+	config  *config.Manager
+	pool    *workerpool.Pool
+	lgr     *logger.Logger
 }
 
-func NewHandler(service *service.Service, cfg *config.Config) *Handler {
+func NewHandler(service *service.Service, cfg *config.Manager, pool *workerpool.Pool, lgr *logger.Logger) *Handler {
 	return &Handler{
-		service:    service,
-		cfg:        cfg,
-		workerPool: make(chan struct{}, cfg.MaxWorkers),
+		service: service,
+		config:  cfg,
+		pool:    pool,
+		lgr:     lgr,
 	}
 }
 
+// cfg текущая конфигурация с учетом перезагрузок
+func (h *Handler) cfg() *config.Config {
+	return h.config.Get()
+}
+
 func (h *Handler) InitRoutes() *gin.Engine {
 
 	router := gin.New()
 
-	router.Use()
+	router.Use(
+		middleware.TracingMiddleware(),
+		middleware.RequestIDMiddleware(h.lgr),
+		middleware.AccessLogMiddleware(h.lgr),
+		middleware.RecoveryMiddleware(h.lgr),
+	)
 
 	api := router.Group("/api")
-	api.Use(middleware.BearerAuthMiddleware(h.cfg))
+	api.Use(middleware.BearerAuthMiddleware(h.config))
 	{
 		api.POST("screen", h.Make)
+		api.POST("diff", h.Diff)
+		api.GET("templates", h.ListTemplates)
+		api.POST("templates/:name/render", h.RenderTemplate)
 	}
 
 	router.GET("/metrics", gin.WrapH(promhttp.Handler()))
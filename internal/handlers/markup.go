@@ -0,0 +1,52 @@
+package handlers
+
+import (
+	"fmt"
+	"os"
+	"screenshoter/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// document html страницы из параметра html, markdown или text.
+// Markdown и текст оформляются стилем из конфигурации, тему подсветки кода можно задать в code_theme
+func (h *Handler) document(ctx *gin.Context) (string, error) {
+	inputs := map[string]string{}
+	for _, name := range []string{service.InputHTML, service.InputMarkdown, service.InputText} {
+		if v := ctx.PostForm(name); v != "" {
+			inputs[name] = v
+		}
+	}
+	if len(inputs) > 1 {
+		return "", fmt.Errorf("only one of html, markdown or text is allowed")
+	}
+	if html, ok := inputs[service.InputHTML]; ok || len(inputs) == 0 {
+		return html, nil
+	}
+
+	style, err := h.markupStyle(ctx.DefaultPostForm("code_theme", h.cfg().CodeTheme))
+	if err != nil {
+		return "", err
+	}
+	if src, ok := inputs[service.InputMarkdown]; ok {
+		return service.MarkdownToHTML(src, style)
+	}
+	return service.TextToHTML(inputs[service.InputText], style)
+}
+
+// markupStyle оформление страниц из markdown и текста
+func (h *Handler) markupStyle(codeTheme string) (service.MarkupStyle, error) {
+	if !service.CodeThemeExists(codeTheme) {
+		return service.MarkupStyle{}, fmt.Errorf("unknown code_theme %q", codeTheme)
+	}
+	style := service.MarkupStyle{CodeTheme: codeTheme}
+
+	if path := h.cfg().MarkupStylesheet; path != "" {
+		css, err := os.ReadFile(path)
+		if err != nil {
+			return style, fmt.Errorf("failed to read markup stylesheet: %w", err)
+		}
+		style.Stylesheet = string(css)
+	}
+	return style, nil
+}
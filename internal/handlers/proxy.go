@@ -0,0 +1,47 @@
+package handlers
+
+import (
+	"errors"
+	"screenshoter/config"
+	"screenshoter/internal/middleware"
+	"screenshoter/internal/options"
+	"screenshoter/internal/service"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errProxyScope запрос задает прокси без разрешения proxy
+var errProxyScope = errors.New("setting the proxy requires an api key with proxy scope")
+
+// errProxyDirect запрос задает прокси для ключа, которому оператор отключил прокси
+var errProxyDirect = errors.New("the api key is configured to connect without a proxy")
+
+// proxyForm прокси из полей формы, nil - поля не заданы
+func proxyForm(ctx *gin.Context) *service.Proxy {
+	p := service.Proxy{
+		Server:   ctx.PostForm("proxy_server"),
+		Bypass:   ctx.PostForm("proxy_bypass"),
+		Username: ctx.PostForm("proxy_username"),
+		Password: service.Secret(ctx.PostForm("proxy_password")),
+	}
+	if p == (service.Proxy{}) {
+		return nil
+	}
+	return &p
+}
+
+// proxy прокси запроса. Задать свой прокси можно только с разрешением proxy
+// и только если оператор не отключил прокси для ключа
+func (h *Handler) proxy(ctx *gin.Context, requested *service.Proxy) (*service.Proxy, error) {
+	key := middleware.APIKey(ctx)
+	if requested == nil {
+		return options.Proxy(h.cfg().KeyProxy(key)), nil
+	}
+	if !key.HasScope(config.ScopeProxy) {
+		return nil, errProxyScope
+	}
+	if key.DirectProxy() {
+		return nil, errProxyDirect
+	}
+	return requested, requested.Validate()
+}
@@ -0,0 +1,143 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/png"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"screenshoter/config"
+	"screenshoter/internal/service"
+	"screenshoter/internal/workerpool"
+	"screenshoter/pkg/logger"
+	"testing"
+)
+
+// proxyTestConfig общий прокси сервера и ключи: без прокси, со своим прокси, с отключенным прокси
+// и с разрешением proxy со своим и с отключенным прокси
+const proxyTestConfig = `
+proxy_server: http://server:3128
+api_keys:
+  - name: plain
+    token: plain-token
+  - name: own
+    token: own-token
+    proxy: {server: "http://key:3128"}
+  - name: direct
+    token: direct-token
+    proxy: {}
+  - name: scoped
+    token: scoped-token
+    scopes: [proxy]
+    proxy: {server: "http://key:3128"}
+  - name: scoped-direct
+    token: scoped-direct-token
+    scopes: [proxy]
+    proxy: {}
+`
+
+// recordingScreenshot запоминает параметры последнего рендера
+type recordingScreenshot struct {
+	opts service.ScreenshotOptions
+}
+
+func (r *recordingScreenshot) Make(_ context.Context, _ string, opts service.ScreenshotOptions) (*service.Result, error) {
+	r.opts = opts
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 1, 1))); err != nil {
+		return nil, err
+	}
+	return &service.Result{Image: buf.Bytes(), ContentType: "image/png"}, nil
+}
+
+func TestRequestProxy(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "config.yaml")
+	if err := os.WriteFile(path, []byte(proxyTestConfig), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	cfg, err := config.Load(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	renderer := &recordingScreenshot{}
+	h := NewHandler(service.NewService(renderer, nil, nil), config.NewManager(cfg), workerpool.New(1), logger.NewLogger(cfg))
+	router := h.InitRoutes()
+
+	tests := []struct {
+		name       string
+		token      string
+		form       map[string]string
+		wantStatus int
+		wantServer string // Прокси рендера, пусто - без прокси
+		wantBypass string
+	}{
+		{name: "server proxy", token: "plain-token", wantStatus: http.StatusOK, wantServer: "http://server:3128"},
+		{name: "key proxy over server proxy", token: "own-token", wantStatus: http.StatusOK, wantServer: "http://key:3128"},
+		{name: "key without proxy", token: "direct-token", wantStatus: http.StatusOK},
+		{name: "request proxy without scope", token: "own-token", form: map[string]string{"proxy_server": "http://request:3128"},
+			wantStatus: http.StatusForbidden},
+		{name: "request proxy over server proxy without scope", token: "plain-token", form: map[string]string{"proxy_server": "http://request:3128"},
+			wantStatus: http.StatusForbidden},
+		{name: "request proxy for direct key without scope", token: "direct-token", form: map[string]string{"proxy_server": "http://request:3128"},
+			wantStatus: http.StatusForbidden},
+		{name: "request proxy for direct key with scope", token: "scoped-direct-token", form: map[string]string{"proxy_server": "http://request:3128"},
+			wantStatus: http.StatusForbidden},
+		{name: "direct key with scope", token: "scoped-direct-token", wantStatus: http.StatusOK},
+		{name: "request proxy with scope", token: "scoped-token",
+			form:       map[string]string{"proxy_server": "http://request:3128", "proxy_bypass": ".internal, localhost"},
+			wantStatus: http.StatusOK, wantServer: "http://request:3128", wantBypass: ".internal, localhost"},
+		{name: "invalid request proxy", token: "scoped-token", form: map[string]string{"proxy_server": "ftp://request:21"},
+			wantStatus: http.StatusBadRequest},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			renderer.opts = service.ScreenshotOptions{}
+			form := map[string]string{"html": "<p>proxy</p>"}
+			for k, v := range tt.form {
+				form[k] = v
+			}
+			rec := httptest.NewRecorder()
+			router.ServeHTTP(rec, multipartRequest(t, "/api/screen", tt.token, form))
+
+			if rec.Code != tt.wantStatus {
+				t.Fatalf("status = %d, want %d: %s", rec.Code, tt.wantStatus, rec.Body)
+			}
+			if tt.wantStatus != http.StatusOK {
+				return
+			}
+			got := renderer.opts.Proxy
+			if tt.wantServer == "" {
+				if got != nil {
+					t.Fatalf("proxy = %+v, want none", got)
+				}
+				return
+			}
+			if got == nil || got.Server != tt.wantServer || got.Bypass != tt.wantBypass {
+				t.Fatalf("proxy = %+v, want server %s bypass %q", got, tt.wantServer, tt.wantBypass)
+			}
+		})
+	}
+}
+
+// multipartRequest POST-запрос с полями формы и токеном ключа
+func multipartRequest(t *testing.T, target, token string, fields map[string]string) *http.Request {
+	t.Helper()
+	var body bytes.Buffer
+	w := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := w.WriteField(name, value); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := w.Close(); err != nil {
+		t.Fatal(err)
+	}
+	req := httptest.NewRequest(http.MethodPost, target, &body)
+	req.Header.Set("Content-Type", w.FormDataContentType())
+	req.Header.Set("Authorization", "Bearer "+token)
+	return req
+}
@@ -1,11 +1,63 @@
 package handlers
 
-import "github.com/gin-gonic/gin"
+import (
+	"errors"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"screenshoter/internal/metrics"
+	"screenshoter/internal/middleware"
+	"screenshoter/internal/service"
+)
 
 type errorResponse struct {
-	Message string `json:"message"`
+	Message     string                     `json:"message"`
+	Limit       *service.LimitError        `json:"limit,omitempty"`       // Нарушенное ограничение сервера
+	Diagnostics *service.Diagnostics       `json:"diagnostics,omitempty"` // События страницы, если рендер прерван из-за них
+	Template    *service.TemplateDataError `json:"template,omitempty"`    // Поле данных, на котором остановился шаблон
+	Action      *service.ActionError       `json:"action,omitempty"`      // Действие со страницей, которое не выполнено
+	Selection   *service.SelectionError    `json:"selection,omitempty"`   // Выделение, которое нельзя нарисовать
+	Annotation  *service.AnnotationError   `json:"annotation,omitempty"`  // Аннотация, которую нельзя нарисовать
+	RequestID   string                     `json:"request_id,omitempty"`  // Идентификатор запроса, как в X-Request-ID
 }
 
 func newErrorResponse(c *gin.Context, statusCode int, message string) {
-	c.AbortWithStatusJSON(statusCode, errorResponse{message})
+	c.AbortWithStatusJSON(statusCode, errorResponse{Message: message, RequestID: middleware.RequestID(c)})
+}
+
+// optionsErrorResponse отвечает 400 на некорректные параметры или превышение ограничений
+func optionsErrorResponse(c *gin.Context, err error) {
+	metrics.TotalRequests.WithLabelValues("400").Inc()
+
+	var limitErr *service.LimitError
+	if errors.As(err, &limitErr) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse{Message: err.Error(), Limit: limitErr, RequestID: middleware.RequestID(c)})
+		return
+	}
+	var actionErr *service.ActionError
+	if errors.As(err, &actionErr) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse{Message: err.Error(), Action: actionErr, RequestID: middleware.RequestID(c)})
+		return
+	}
+	var selectionErr *service.SelectionError
+	if errors.As(err, &selectionErr) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse{Message: err.Error(), Selection: selectionErr, RequestID: middleware.RequestID(c)})
+		return
+	}
+	var annotationErr *service.AnnotationError
+	if errors.As(err, &annotationErr) {
+		c.AbortWithStatusJSON(http.StatusBadRequest, errorResponse{Message: err.Error(), Annotation: annotationErr, RequestID: middleware.RequestID(c)})
+		return
+	}
+	newErrorResponse(c, http.StatusBadRequest, err.Error())
+}
+
+// scopeErrorResponse 403, если для параметров запроса у ключа нет разрешения, иначе 400
+func scopeErrorResponse(c *gin.Context, err error) {
+	if errors.Is(err, errDebugScope) || errors.Is(err, errWatermarkScope) || errors.Is(err, errProxyScope) ||
+		errors.Is(err, errProxyDirect) {
+		metrics.TotalRequests.WithLabelValues("403").Inc()
+		newErrorResponse(c, http.StatusForbidden, err.Error())
+		return
+	}
+	optionsErrorResponse(c, err)
 }
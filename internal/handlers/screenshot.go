@@ -1,175 +1,545 @@
 package handlers
 
 import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"github.com/getsentry/sentry-go"
 	"github.com/gin-gonic/gin"
-	"github.com/prometheus/client_golang/prometheus"
 	"net/http"
+	"screenshoter/config"
+	"screenshoter/internal/metrics"
+	"screenshoter/internal/middleware"
+	"screenshoter/internal/options"
 	"screenshoter/internal/service"
+	"screenshoter/pkg/logger"
 	"strconv"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
 )
 
-// метрики для prometheus
-var (
-	activeWorkersGauge = prometheus.NewGauge(prometheus.GaugeOpts{
-		Name: "screenshot_service_active_workers",
-		Help: "Current number of active screenshot workers",
-	})
+// errDebugScope отладочные артефакты запрошены ключом без разрешения debug
+var errDebugScope = errors.New("debug_artifacts requires an api key with debug scope")
 
-	totalRequestsCounter = prometheus.NewCounterVec(
-		prometheus.CounterOpts{
-			Name: "screenshot_service_requests_total",
-			Help: "Total number of screenshot requests",
-		},
-		[]string{"status"},
-	)
-
-	requestDurationHistogram = prometheus.NewHistogramVec(
-		prometheus.HistogramOpts{
-			Name:    "screenshot_service_request_duration_seconds",
-			Help:    "Duration of screenshot requests",
-			Buckets: []float64{0.1, 0.5, 1, 2, 5, 10},
-		},
-		[]string{"browser"},
-	)
-)
+// tracer спаны этапов обработки запроса
+var tracer = otel.Tracer("screenshoter/internal/handlers")
 
-func init() {
-	// Регистрируем метрики
-	prometheus.MustRegister(activeWorkersGauge)
-	prometheus.MustRegister(totalRequestsCounter)
-	prometheus.MustRegister(requestDurationHistogram)
+// debugResponse ответ в отладочном режиме (debug=true)
+type debugResponse struct {
+	ContentType string              `json:"content_type"`
+	Image       string              `json:"image"` // Изображение в base64
+	Diagnostics service.Diagnostics `json:"diagnostics"`
 }
 
 func (h *Handler) Make(ctx *gin.Context) {
 
 	startTime := time.Now()
 
-	// Определяем браузер
-	defaultBrowser := service.BrowserChromium
-	browser := ctx.PostForm("browser")
-	switch browser {
-	case "firefox":
-		defaultBrowser = service.BrowserFirefox
-	case "webkit":
-		defaultBrowser = service.BrowserWebkit
-	}
-
+	var opts service.ScreenshotOptions
 	defer func() {
-		if ctx.Writer.Status() >= 200 && ctx.Writer.Status() < 300 {
-			// Записываем продолжительность запроса
-			requestDurationHistogram.WithLabelValues(browser).Observe(time.Since(startTime).Seconds())
-		}
+		// Записываем продолжительность запроса, в том числе неуспешного
+		observeRequest(ctx, opts, startTime)
 	}()
 
-	html := ctx.PostForm("html")
-	if html == "" {
-		totalRequestsCounter.WithLabelValues("400").Inc()
-		newErrorResponse(ctx, http.StatusBadRequest, "html content is required")
+	opts, err := h.formOptions(ctx)
+	if err != nil {
+		scopeErrorResponse(ctx, err)
 		return
 	}
 
-	// Пытаемся занять слот в пуле воркеров
-	select {
-	case h.workerPool <- struct{}{}: // Получили слот
-		activeWorkersGauge.Inc() // Увеличиваем счетчик активных воркеров
+	// html, markdown или текст
+	html, err := h.document(ctx)
+	if err != nil {
+		optionsErrorResponse(ctx, err)
+		return
+	}
+	setRenderInfo(ctx, opts)
+
+	// Страница с картинками, стилями и шрифтами
+	if opts.Bundle, err = h.bundle(ctx, html); err != nil {
+		optionsErrorResponse(ctx, err)
+		return
+	}
+	if opts.Bundle != nil {
 		defer func() {
-			<-h.workerPool
-			activeWorkersGauge.Dec() // Уменьшаем счетчик при освобождении
+			if err := opts.Bundle.Close(); err != nil {
+				logger.FromContext(ctx.Request.Context(), h.lgr).Warn().Msgf("failed to remove bundle: %v", err)
+			}
 		}()
-	case <-ctx.Request.Context().Done():
-		totalRequestsCounter.WithLabelValues("429").Inc()
-		newErrorResponse(ctx, http.StatusTooManyRequests, "request cancelled by client")
+	} else if html == "" {
+		metrics.TotalRequests.WithLabelValues("400").Inc()
+		newErrorResponse(ctx, http.StatusBadRequest, "html, markdown or text content is required")
 		return
-	default:
-		totalRequestsCounter.WithLabelValues("429").Inc()
-		newErrorResponse(ctx, http.StatusTooManyRequests, "server busy, try again later")
+	}
+
+	debug, err := parseBool(ctx, "debug")
+	if err != nil {
+		optionsErrorResponse(ctx, err)
 		return
 	}
 
+	_, queueSpan := tracer.Start(ctx.Request.Context(), "worker.queue_wait")
+	release, ok := h.acquireWorker(ctx)
+	queueSpan.SetAttributes(attribute.Bool("worker.acquired", ok))
+	queueSpan.End()
+	if !ok {
+		return
+	}
+	defer release()
+
+	res, ok := h.render(ctx, html, opts)
+	if !ok {
+		return
+	}
+	metrics.TotalRequests.WithLabelValues("200").Inc()
+
+	_, encodeSpan := tracer.Start(ctx.Request.Context(), "response.encode",
+		trace.WithAttributes(attribute.Int("http.response.body.size", len(res.Image))))
+	encodeStart := time.Now()
+	if res.Artifacts != nil {
+		h.artifactsResponse(ctx, res)
+	} else if debug {
+		// Отладочный режим: изображение и события страницы одним JSON
+		ctx.JSON(http.StatusOK, debugResponse{
+			ContentType: res.ContentType,
+			Image:       base64.StdEncoding.EncodeToString(res.Image),
+			Diagnostics: res.Diagnostics,
+		})
+	} else {
+		ctx.Data(http.StatusOK, res.ContentType, res.Image)
+	}
+	metrics.PhaseDuration.WithLabelValues("encode", string(opts.Browser)).Observe(time.Since(encodeStart).Seconds())
+	encodeSpan.End()
+}
+
+// setRenderInfo передает браузер, формат и сводку параметров в журнал запросов и события Sentry
+func setRenderInfo(ctx *gin.Context, opts service.ScreenshotOptions) {
+	ctx.Set(middleware.BrowserContextKey, string(opts.Browser))
+	ctx.Set(middleware.TypeContextKey, opts.OutputType())
+
+	middleware.SetSentryTag(ctx, "browser", string(opts.Browser))
+	middleware.SetSentryTag(ctx, "type", opts.OutputType())
+	summary := sentry.Context{
+		"full_page":  opts.FullPage,
+		"timeout":    opts.Timeout,
+		"selections": len(opts.Selections),
+		"scroll":     fmt.Sprintf("%d,%d", opts.ScrollX, opts.ScrollY),
+	}
+	if opts.Viewport != nil {
+		summary["viewport"] = fmt.Sprintf("%dx%d", opts.Viewport.Width, opts.Viewport.Height)
+	}
+	if opts.URL != "" {
+		summary["url"] = opts.URL
+	}
+	middleware.SetSentryContext(ctx, "screenshot", summary)
+}
+
+// observeRequest записывает длительность запроса с итоговым статусом,
+// браузером и форматом; если параметры не разобраны, браузер и формат - unknown
+func observeRequest(ctx *gin.Context, opts service.ScreenshotOptions, startTime time.Time) {
+	browser, outputType := "unknown", "unknown"
+	if opts.Browser != "" {
+		browser, outputType = string(opts.Browser), opts.OutputType()
+	}
+	metrics.RequestDuration.
+		WithLabelValues(browser, outputType, strconv.Itoa(ctx.Writer.Status())).
+		Observe(time.Since(startTime).Seconds())
+}
+
+// acquireWorker занимает слот в пуле воркеров, при неудаче отвечает клиенту ошибкой
+func (h *Handler) acquireWorker(ctx *gin.Context) (func(), bool) {
+	if ctx.Request.Context().Err() != nil {
+		metrics.TotalRequests.WithLabelValues("429").Inc()
+		newErrorResponse(ctx, http.StatusTooManyRequests, "request cancelled by client")
+		return nil, false
+	}
+
+	// Ждем свободный воркер не дольше queue_timeout и пока клиент не отключился
+	waitCtx, cancel := context.WithTimeout(ctx.Request.Context(), time.Duration(h.cfg().QueueTimeout)*time.Millisecond)
+	defer cancel()
+	waitStart := time.Now()
+	release, err := h.pool.Acquire(waitCtx)
+	metrics.QueueWait.Observe(time.Since(waitStart).Seconds())
+	if err != nil {
+		metrics.TotalRequests.WithLabelValues("429").Inc()
+		if ctx.Request.Context().Err() != nil {
+			newErrorResponse(ctx, http.StatusTooManyRequests, "request cancelled by client")
+			return nil, false
+		}
+		newErrorResponse(ctx, http.StatusTooManyRequests, "server busy, try again later")
+		return nil, false
+	}
+	return release, true
+}
+
+// screenshotOptions собирает настройки скриншота из параметров запроса
+// и проверяет их на соответствие ограничениям сервера
+func (h *Handler) screenshotOptions(ctx *gin.Context) (service.ScreenshotOptions, error) {
+	cfg := h.cfg()
+
+	// Определяем браузер
+	browser, err := service.ParseBrowser(ctx.PostForm("browser"))
+	if err != nil {
+		return service.ScreenshotOptions{}, err
+	}
+
 	// Получаем параметры выделенной области
 	var selection *service.SelectionArea
 	if ctx.PostForm("x") != "" {
 		selection = &service.SelectionArea{
-			X:      parseInt(ctx.PostForm("x")),
-			Y:      parseInt(ctx.PostForm("y")),
-			Width:  parseInt(ctx.PostForm("width")),
-			Height: parseInt(ctx.PostForm("height")),
+			X:        parseInt(ctx.PostForm("x")),
+			Y:        parseInt(ctx.PostForm("y")),
+			Width:    parseInt(ctx.PostForm("width")),
+			Height:   parseInt(ctx.PostForm("height")),
+			Space:    ctx.PostForm("selection_space"),
+			Selector: ctx.PostForm("selection_selector"),
 		}
-		fmt.Println(selection)
 	}
 
-	// Настройки скриншота
+	// Настройки скриншота, значения по умолчанию берутся из конфигурации
 	opts := service.ScreenshotOptions{
-		Browser:        defaultBrowser,
-		Type:           h.cfg.Type,
-		Quality:        nil,
-		FullPage:       true,
-		OmitBackground: false,
-		Viewport: (*struct {
+		Browser:         browser,
+		Type:            ctx.DefaultPostForm("type", cfg.Type),
+		Quality:         nil,
+		FullPage:        cfg.FullPage,
+		OmitBackground:  false,
+		Timeout:         float64(cfg.Timeout),
+		SelectionStyle:  h.selectionStyle(),
+		AnnotationStyle: h.annotationStyle(),
+		ScrollX:         parseInt(ctx.PostForm("scrollx")),
+		ScrollY:         parseInt(ctx.PostForm("scrolly")),
+		DocumentMode:    ctx.DefaultPostForm("document_mode", cfg.DocumentMode),
+		BaseURL:         ctx.PostForm("base_url"),
+		ScrollContainer: ctx.PostForm("scroll_container"),
+		LaunchPreset:    ctx.PostForm("launch_preset"),
+		DocumentOrigin:  cfg.DocumentOrigin,
+		Emulation: service.Emulation{
+			ColorScheme:    ctx.DefaultPostForm("color_scheme", cfg.ColorScheme),
+			ReducedMotion:  ctx.DefaultPostForm("reduced_motion", cfg.ReducedMotion),
+			Locale:         ctx.DefaultPostForm("locale", cfg.Locale),
+			TimezoneID:     ctx.DefaultPostForm("timezone_id", cfg.TimezoneID),
+			Geolocation:    options.Emulation(cfg).Geolocation,
+			AcceptLanguage: ctx.DefaultPostForm("accept_language", cfg.AcceptLanguage),
+			Media:          ctx.DefaultPostForm("media", cfg.Media),
+		},
+	}
+
+	// Размер окна: незаданная сторона берется из размера окна по умолчанию
+	if width, height := ctx.PostForm("visiblewidth"), ctx.PostForm("visibleheight"); width != "" || height != "" {
+		opts.Viewport = &struct {
 			Width  int `json:"width"`
 			Height int `json:"height"`
-		})(&struct {
-			Width  int
-			Height int
-		}{
-			Width:  parseInt(ctx.PostForm("visiblewidth")),
-			Height: parseInt(ctx.PostForm("visibleheight")),
-		}),
-		Timeout: 5000,
-		SelectionStyle: &service.SelectionStyle{
-			BorderColor: h.cfg.SelectionBorderColor,
-			BorderWidth: h.cfg.SelectionBorderWidth,
-			BorderStyle: h.cfg.SelectionBorderStyle,
-			Opacity:     h.cfg.SelectionBorderOpacity,
-		},
-		ScrollX: parseInt(ctx.PostForm("scrollx")),
-		ScrollY: parseInt(ctx.PostForm("scrolly")),
+		}{Width: service.DefaultViewportWidth, Height: service.DefaultViewportHeight}
+		if width != "" {
+			opts.Viewport.Width = parseInt(width)
+		}
+		if height != "" {
+			opts.Viewport.Height = parseInt(height)
+		}
+	}
+	if v := ctx.PostForm("quality"); v != "" {
+		quality, err := strconv.Atoi(v)
+		if err != nil || quality < 0 || quality > 100 {
+			return opts, fmt.Errorf("quality must be an integer between 0 and 100")
+		}
+		opts.Quality = &quality
+	}
+	if v := ctx.PostForm("full_page"); v != "" {
+		if opts.FullPage, err = strconv.ParseBool(v); err != nil {
+			return opts, fmt.Errorf("full_page must be a boolean")
+		}
+	}
+	if v := ctx.PostForm("omit_background"); v != "" {
+		if opts.OmitBackground, err = strconv.ParseBool(v); err != nil {
+			return opts, fmt.Errorf("omit_background must be a boolean")
+		}
+	}
+	if v := ctx.PostForm("timeout"); v != "" {
+		if opts.Timeout, err = strconv.ParseFloat(v, 64); err != nil {
+			return opts, fmt.Errorf("timeout must be a number of milliseconds")
+		}
+	}
+	if opts.FailOnPageError, err = parseBool(ctx, "fail_on_page_error"); err != nil {
+		return opts, err
+	}
+	if err := checkDocumentOptions(opts); err != nil {
+		return opts, err
+	}
+	if v := ctx.PostForm("geolocation"); v != "" {
+		if opts.Geolocation, err = parseGeolocation(v); err != nil {
+			return opts, err
+		}
+	}
+	if err := opts.Emulation.Validate(); err != nil {
+		return opts, err
+	}
+	if opts.Credentials, err = credentials(ctx); err != nil {
+		return opts, err
+	}
+	if v := ctx.PostForm("actions"); v != "" {
+		if err := json.Unmarshal([]byte(v), &opts.Actions); err != nil {
+			return opts, fmt.Errorf("actions must be a JSON array: %w", err)
+		}
+		if err := service.ValidateActions(opts.Actions); err != nil {
+			return opts, err
+		}
 	}
 
-	// Если есть выделенная область, добавляем ее
+	// Выделенные области: одна из полей формы и/или список selections
 	if selection != nil {
 		opts.Selections = []service.SelectionArea{*selection}
 	}
+	if v := ctx.PostForm("selections"); v != "" {
+		var selections []service.SelectionArea
+		if err := json.Unmarshal([]byte(v), &selections); err != nil {
+			return opts, fmt.Errorf("selections must be a JSON array: %w", err)
+		}
+		opts.Selections = append(opts.Selections, selections...)
+	}
+	if err := service.ValidateSelections(opts.Selections); err != nil {
+		return opts, err
+	}
+	if v := ctx.PostForm("annotations"); v != "" {
+		if err := json.Unmarshal([]byte(v), &opts.Annotations); err != nil {
+			return opts, fmt.Errorf("annotations must be a JSON array: %w", err)
+		}
+	}
+	if opts.Spotlight, err = parseBool(ctx, "spotlight"); err != nil {
+		return opts, err
+	}
+	opts.SpotlightOpacity = cfg.SpotlightOpacity
+	if v := ctx.PostForm("spotlight_opacity"); v != "" {
+		if opts.SpotlightOpacity, err = strconv.ParseFloat(v, 64); err != nil {
+			return opts, fmt.Errorf("spotlight_opacity must be a number")
+		}
+	}
+	if err := service.ValidateAnnotations(opts); err != nil {
+		return opts, err
+	}
+	if v := ctx.PostForm("duration"); v != "" {
+		if opts.Duration, err = strconv.ParseFloat(v, 64); err != nil {
+			return opts, fmt.Errorf("duration must be a number of milliseconds")
+		}
+	}
+	if v := ctx.PostForm("frame_rate"); v != "" {
+		if opts.FrameRate, err = strconv.Atoi(v); err != nil {
+			return opts, fmt.Errorf("frame_rate must be an integer")
+		}
+	}
+	if err := service.ValidateAnimation(opts); err != nil {
+		return opts, err
+	}
+	if opts.DebugArtifacts, err = parseBool(ctx, "debug_artifacts"); err != nil {
+		return opts, err
+	}
+	if opts.Watermark, err = watermarkForm(ctx); err != nil {
+		return opts, err
+	}
+	opts.Proxy = proxyForm(ctx)
+
+	return opts, options.Limits(h.cfg()).Apply(&opts)
+}
+
+// formOptions настройки скриншота из формы с учетом разрешений ключа
+func (h *Handler) formOptions(ctx *gin.Context) (service.ScreenshotOptions, error) {
+	opts, err := h.screenshotOptions(ctx)
+	if err != nil {
+		return opts, err
+	}
+	noWatermark, err := parseBool(ctx, "no_watermark")
+	if err != nil {
+		return opts, err
+	}
+	return opts, h.keyOptions(ctx, &opts, noWatermark)
+}
+
+// keyOptions заменяет запрошенные отладочные артефакты, водяной знак и прокси итоговыми
+// с учетом настроек и разрешений ключа. Вызывается всеми обработчиками, которые рендерят страницу
+func (h *Handler) keyOptions(ctx *gin.Context, opts *service.ScreenshotOptions, noWatermark bool) error {
+	var err error
+	if opts.DebugArtifacts && !middleware.APIKey(ctx).HasScope(config.ScopeDebug) {
+		return errDebugScope
+	}
+	if opts.Watermark, err = h.watermark(ctx, opts.Watermark, noWatermark); err != nil {
+		return err
+	}
+	opts.Proxy, err = h.proxy(ctx, opts.Proxy)
+	return err
+}
+
+// checkDocumentOptions проверяет способ загрузки html
+func checkDocumentOptions(opts service.ScreenshotOptions) error {
+	if opts.DocumentMode != service.DocumentFile && opts.DocumentMode != service.DocumentHTTP {
+		return fmt.Errorf("document_mode must be file or http")
+	}
+	if opts.BaseURL != "" {
+		if _, err := service.ParseBaseURL(opts.BaseURL); err != nil {
+			return fmt.Errorf("base_url: %w", err)
+		}
+	}
+	return nil
+}
+
+// parseGeolocation разбирает координаты в формате "широта,долгота[,точность]"
+func parseGeolocation(value string) (*service.Geolocation, error) {
+	g, err := config.ParseGeolocation(value)
+	if err != nil {
+		return nil, err
+	}
+	return options.Geolocation(g), nil
+}
+
+// emulation эмуляция настроек пользователя по умолчанию из конфигурации
+func (h *Handler) emulation() service.Emulation {
+	return options.Emulation(h.cfg())
+}
 
+// annotationStyle стиль аннотаций по умолчанию из конфигурации
+func (h *Handler) annotationStyle() *service.AnnotationStyle {
+	cfg := h.cfg()
+	return &service.AnnotationStyle{
+		Color:       cfg.AnnotationColor,
+		TextColor:   cfg.AnnotationTextColor,
+		FontSize:    cfg.AnnotationFontSize,
+		StrokeWidth: cfg.AnnotationStrokeWidth,
+	}
+}
+
+// selectionStyle стиль выделения из конфигурации
+func (h *Handler) selectionStyle() *service.SelectionStyle {
+	cfg := h.cfg()
+	return &service.SelectionStyle{
+		BorderColor: cfg.SelectionBorderColor,
+		BorderWidth: cfg.SelectionBorderWidth,
+		BorderStyle: cfg.SelectionBorderStyle,
+		Opacity:     cfg.SelectionBorderOpacity,
+	}
+}
+
+// render создает скриншот с ограничением по времени, при ошибке отвечает клиенту
+func (h *Handler) render(ctx *gin.Context, html string, opts service.ScreenshotOptions) (*service.Result, bool) {
 	// Канал для результата
 	resultChan := make(chan struct {
-		bytes       []byte
-		contentType string
-		err         error
+		res *service.Result
+		err error
 	}, 1)
 
-	// Запускаем создание скриншота в горутине
+	// Запускаем создание скриншота в горутине. RecoveryMiddleware не видит паники
+	// этой горутины, поэтому она перехватывается здесь и превращается в ошибку
 	go func() {
-		bytes, contentType, err := h.service.Screenshot.Make(html, opts)
+		defer func() {
+			if rec := recover(); rec != nil {
+				resultChan <- struct {
+					res *service.Result
+					err error
+				}{nil, logger.NewPanicError(rec)}
+			}
+		}()
+		res, err := h.service.Screenshot.Make(ctx.Request.Context(), html, opts)
 		resultChan <- struct {
-			bytes       []byte
-			contentType string
-			err         error
-		}{bytes, contentType, err}
+			res *service.Result
+			err error
+		}{res, err}
 	}()
 
 	// Ждем результат с таймаутом
 	select {
 	case result := <-resultChan:
 		if result.err != nil {
-			totalRequestsCounter.WithLabelValues("500").Inc()
+			var limitErr *service.LimitError
+			if errors.As(result.err, &limitErr) {
+				optionsErrorResponse(ctx, limitErr)
+				return nil, false
+			}
+			var actionErr *service.ActionError
+			if errors.As(result.err, &actionErr) {
+				metrics.TotalRequests.WithLabelValues("422").Inc()
+				ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, errorResponse{
+					Message:   actionErr.Error(),
+					Action:    actionErr,
+					RequestID: middleware.RequestID(ctx),
+				})
+				return nil, false
+			}
+			var selectionErr *service.SelectionError
+			if errors.As(result.err, &selectionErr) {
+				metrics.TotalRequests.WithLabelValues("422").Inc()
+				ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, errorResponse{
+					Message:   selectionErr.Error(),
+					Selection: selectionErr,
+					RequestID: middleware.RequestID(ctx),
+				})
+				return nil, false
+			}
+			var annotationErr *service.AnnotationError
+			if errors.As(result.err, &annotationErr) {
+				metrics.TotalRequests.WithLabelValues("422").Inc()
+				ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, errorResponse{
+					Message:    annotationErr.Error(),
+					Annotation: annotationErr,
+					RequestID:  middleware.RequestID(ctx),
+				})
+				return nil, false
+			}
+			var panicErr *logger.PanicError
+			if errors.As(result.err, &panicErr) {
+				logger.FromContext(ctx.Request.Context(), h.lgr).Exception(panicErr).Msg("Panic recovered in render")
+				metrics.TotalRequests.WithLabelValues("500").Inc()
+				newErrorResponse(ctx, http.StatusInternalServerError, "internal server error")
+				return nil, false
+			}
+			var pageErr *service.PageError
+			if errors.As(result.err, &pageErr) {
+				metrics.TotalRequests.WithLabelValues("422").Inc()
+				setDiagnosticsHeaders(ctx, pageErr.Diagnostics)
+				ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, errorResponse{
+					Message:     pageErr.Error(),
+					Diagnostics: &pageErr.Diagnostics,
+					RequestID:   middleware.RequestID(ctx),
+				})
+				return nil, false
+			}
+			logger.FromContext(ctx.Request.Context(), h.lgr).Exception(result.err).Msg("Screenshot generation failed")
+			metrics.TotalRequests.WithLabelValues("500").Inc()
 			newErrorResponse(ctx, http.StatusInternalServerError, result.err.Error())
-			return
+			return nil, false
 		}
-		totalRequestsCounter.WithLabelValues("200").Inc()
-		ctx.Data(http.StatusOK, result.contentType, result.bytes)
+		setDiagnosticsHeaders(ctx, result.res.Diagnostics)
+		return result.res, true
 	case <-ctx.Request.Context().Done():
-		totalRequestsCounter.WithLabelValues("499").Inc()
+		metrics.TotalRequests.WithLabelValues("499").Inc()
 		newErrorResponse(ctx, http.StatusRequestTimeout, "request timeout")
-		return
-	case <-time.After(20 * time.Second): // Таймаут на выполнение
-		totalRequestsCounter.WithLabelValues("504").Inc()
+		return nil, false
+	case <-time.After(service.RenderTimeout(opts)): // Таймаут на выполнение
+		metrics.TotalRequests.WithLabelValues("504").Inc()
 		newErrorResponse(ctx, http.StatusRequestTimeout, "screenshot generation timeout")
-		return
+		return nil, false
 	}
+}
 
+// setDiagnosticsHeaders количество событий страницы в заголовках X-Render-*
+func setDiagnosticsHeaders(ctx *gin.Context, d service.Diagnostics) {
+	ctx.Header("X-Render-Console-Messages", strconv.Itoa(len(d.Console)))
+	ctx.Header("X-Render-Page-Errors", strconv.Itoa(len(d.PageErrors)))
+	ctx.Header("X-Render-Failed-Requests", strconv.Itoa(len(d.FailedRequests)))
+}
+
+// parseBool необязательный логический параметр формы, по умолчанию false
+func parseBool(ctx *gin.Context, name string) (bool, error) {
+	v := ctx.PostForm(name)
+	if v == "" {
+		return false, nil
+	}
+	b, err := strconv.ParseBool(v)
+	if err != nil {
+		return false, fmt.Errorf("%s must be a boolean", name)
+	}
+	return b, nil
 }
 
 func parseInt(s string) int {
@@ -180,8 +550,8 @@ func parseInt(s string) int {
 // MetricsHandler Дополнительные кастомные метрики
 func (h *Handler) MetricsHandler(ctx *gin.Context) {
 	ctx.JSON(http.StatusOK, gin.H{
-		"active_workers": len(h.workerPool),
-		"max_workers":    h.cfg.MaxWorkers,
-		"queue_size":     h.cfg.MaxWorkers - len(h.workerPool),
+		"active_workers": h.pool.Active(),
+		"max_workers":    h.pool.Size(),
+		"queue_size":     h.pool.Size() - h.pool.Active(),
 	})
 }
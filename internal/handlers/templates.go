@@ -0,0 +1,222 @@
+package handlers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"screenshoter/internal/metrics"
+	"screenshoter/internal/middleware"
+	"screenshoter/internal/options"
+	"screenshoter/internal/service"
+	"time"
+
+	"github.com/gin-gonic/gin"
+)
+
+// templateRenderRequest тело запроса рендера шаблона
+//
+//	{"data": {"user": {"name": "Иван"}}, "options": {"type": "jpeg", "viewport": {"width": 1200, "height": 630}}}
+type templateRenderRequest struct {
+	Data        any             `json:"data"`
+	Options     json.RawMessage `json:"options"`
+	NoWatermark bool            `json:"no_watermark"` // Отключить водяной знак ключа
+}
+
+type templateListResponse struct {
+	Templates []*service.Template `json:"templates"`
+}
+
+// ListTemplates список доступных шаблонов
+func (h *Handler) ListTemplates(ctx *gin.Context) {
+	if h.service.Templates == nil {
+		ctx.JSON(http.StatusOK, templateListResponse{Templates: []*service.Template{}})
+		return
+	}
+	ctx.JSON(http.StatusOK, templateListResponse{Templates: h.service.Templates.List()})
+}
+
+// RenderTemplate подставляет JSON-данные в шаблон и возвращает скриншот результата.
+// ETag зависит только от шаблона, данных и настроек, поэтому повторный запрос
+// с If-None-Match получает 304 без рендера
+func (h *Handler) RenderTemplate(ctx *gin.Context) {
+	startTime := time.Now()
+
+	var opts service.ScreenshotOptions
+	defer func() {
+		observeRequest(ctx, opts, startTime)
+	}()
+
+	if h.service.Templates == nil {
+		metrics.TotalRequests.WithLabelValues("404").Inc()
+		newErrorResponse(ctx, http.StatusNotFound, "templates are not configured")
+		return
+	}
+	tpl, ok := h.service.Templates.Get(ctx.Param("name"))
+	if !ok {
+		metrics.TotalRequests.WithLabelValues("404").Inc()
+		newErrorResponse(ctx, http.StatusNotFound, fmt.Sprintf("template %q not found", ctx.Param("name")))
+		return
+	}
+
+	var req templateRenderRequest
+	decoder := json.NewDecoder(ctx.Request.Body)
+	// Числа подставляются в шаблон в исходной записи, без перевода в float64
+	decoder.UseNumber()
+	if err := decoder.Decode(&req); err != nil {
+		optionsErrorResponse(ctx, fmt.Errorf("invalid request body: %w", err))
+		return
+	}
+
+	opts, err := h.templateOptions(req.Options)
+	if err != nil {
+		optionsErrorResponse(ctx, err)
+		return
+	}
+	setRenderInfo(ctx, opts)
+	middleware.SetSentryTag(ctx, "template", tpl.Name)
+
+	// Знак из options.watermark задается только текстом
+	if err := h.keyOptions(ctx, &opts, req.NoWatermark); err != nil {
+		scopeErrorResponse(ctx, err)
+		return
+	}
+
+	html, err := tpl.Render(req.Data)
+	if err != nil {
+		templateErrorResponse(ctx, err)
+		return
+	}
+
+	etag, err := templateETag(tpl, req.Data, opts)
+	if err != nil {
+		optionsErrorResponse(ctx, err)
+		return
+	}
+	ctx.Header("ETag", etag)
+	cacheHit := ctx.GetHeader("If-None-Match") == etag && !opts.DebugArtifacts
+	ctx.Set(middleware.CacheHitContextKey, cacheHit)
+	if cacheHit {
+		metrics.TotalRequests.WithLabelValues("304").Inc()
+		ctx.Status(http.StatusNotModified)
+		return
+	}
+
+	release, ok := h.acquireWorker(ctx)
+	if !ok {
+		return
+	}
+	defer release()
+
+	res, ok := h.render(ctx, html, opts)
+	if !ok {
+		return
+	}
+	metrics.TotalRequests.WithLabelValues("200").Inc()
+
+	if res.Artifacts != nil {
+		h.artifactsResponse(ctx, res)
+		return
+	}
+	ctx.Data(http.StatusOK, res.ContentType, res.Image)
+}
+
+// templateOptions настройки скриншота из JSON поверх значений по умолчанию из конфигурации
+func (h *Handler) templateOptions(raw json.RawMessage) (service.ScreenshotOptions, error) {
+	cfg := h.cfg()
+	opts := service.ScreenshotOptions{
+		Browser:          service.BrowserChromium,
+		Type:             cfg.Type,
+		FullPage:         cfg.FullPage,
+		Timeout:          float64(cfg.Timeout),
+		SelectionStyle:   h.selectionStyle(),
+		AnnotationStyle:  h.annotationStyle(),
+		SpotlightOpacity: cfg.SpotlightOpacity,
+		DocumentMode:     cfg.DocumentMode,
+		Emulation:        h.emulation(),
+	}
+	if len(raw) > 0 {
+		if err := json.Unmarshal(raw, &opts); err != nil {
+			var typeErr *json.UnmarshalTypeError
+			if errors.As(err, &typeErr) {
+				return opts, fmt.Errorf("options.%s must be %s", typeErr.Field, typeErr.Type)
+			}
+			return opts, fmt.Errorf("invalid options: %w", err)
+		}
+	}
+	// Страница всегда берется из шаблона, поля сервера не задаются клиентом
+	opts.URL = ""
+	opts.DocumentOrigin = cfg.DocumentOrigin
+
+	browser, err := service.ParseBrowser(string(opts.Browser))
+	if err != nil {
+		return opts, err
+	}
+	opts.Browser = browser
+	if opts.Quality != nil && (*opts.Quality < 0 || *opts.Quality > 100) {
+		return opts, fmt.Errorf("quality must be an integer between 0 and 100")
+	}
+	if err := checkDocumentOptions(opts); err != nil {
+		return opts, err
+	}
+	if err := opts.Emulation.Validate(); err != nil {
+		return opts, err
+	}
+	if err := opts.Credentials.Validate(); err != nil {
+		return opts, err
+	}
+	if err := service.ValidateActions(opts.Actions); err != nil {
+		return opts, err
+	}
+	if err := service.ValidateSelections(opts.Selections); err != nil {
+		return opts, err
+	}
+	if err := service.ValidateAnnotations(opts); err != nil {
+		return opts, err
+	}
+	if err := service.ValidateAnimation(opts); err != nil {
+		return opts, err
+	}
+
+	return opts, options.Limits(cfg).Apply(&opts)
+}
+
+// templateETag хеш шаблона, данных и итоговых настроек.
+// json.Marshal сортирует ключи объектов, поэтому порядок полей в запросе не влияет на хеш
+func templateETag(tpl *service.Template, data any, opts service.ScreenshotOptions) (string, error) {
+	hash := sha256.New()
+	hash.Write([]byte(tpl.Hash))
+	for _, v := range []any{data, opts} {
+		encoded, err := json.Marshal(v)
+		if err != nil {
+			return "", err
+		}
+		hash.Write([]byte{0})
+		hash.Write(bytes.TrimSpace(encoded))
+	}
+	// PNG водяного знака не попадает в JSON настроек
+	if opts.Watermark != nil {
+		hash.Write([]byte{0})
+		hash.Write(opts.Watermark.Image)
+	}
+	return `"` + hex.EncodeToString(hash.Sum(nil)) + `"`, nil
+}
+
+// templateErrorResponse отвечает 422, если данные не подходят шаблону
+func templateErrorResponse(ctx *gin.Context, err error) {
+	var dataErr *service.TemplateDataError
+	if errors.As(err, &dataErr) {
+		metrics.TotalRequests.WithLabelValues("422").Inc()
+		ctx.AbortWithStatusJSON(http.StatusUnprocessableEntity, errorResponse{
+			Message:   err.Error(),
+			Template:  dataErr,
+			RequestID: middleware.RequestID(ctx),
+		})
+		return
+	}
+	metrics.TotalRequests.WithLabelValues("500").Inc()
+	newErrorResponse(ctx, http.StatusInternalServerError, err.Error())
+}
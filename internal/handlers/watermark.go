@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"errors"
+	"fmt"
+	"screenshoter/config"
+	"screenshoter/internal/middleware"
+	"screenshoter/internal/options"
+	"screenshoter/internal/service"
+	"strconv"
+
+	"github.com/gin-gonic/gin"
+)
+
+// errWatermarkScope запрос меняет водяной знак ключа без разрешения watermark
+var errWatermarkScope = errors.New("changing the watermark requires an api key with watermark scope")
+
+// watermarkParams параметры водяного знака в форме запроса
+var watermarkParams = []string{"watermark_text", "watermark_position", "watermark_opacity", "watermark_tile",
+	"watermark_margin", "watermark_color", "watermark_font_size"}
+
+// watermarkForm водяной знак из полей формы, nil - поля не заданы
+func watermarkForm(ctx *gin.Context) (*service.Watermark, error) {
+	var w service.Watermark
+	set := false
+	for _, name := range watermarkParams {
+		if ctx.PostForm(name) != "" {
+			set = true
+		}
+	}
+	if _, err := ctx.FormFile("watermark_image"); err == nil {
+		image, err := readFormFile(ctx, "watermark_image")
+		if err != nil {
+			return nil, err
+		}
+		w.Image, set = image, true
+	}
+	if !set {
+		return nil, nil
+	}
+
+	w.Text = ctx.PostForm("watermark_text")
+	w.Position = ctx.PostForm("watermark_position")
+	w.Color = ctx.PostForm("watermark_color")
+	var err error
+	if v := ctx.PostForm("watermark_opacity"); v != "" {
+		if w.Opacity, err = strconv.ParseFloat(v, 64); err != nil {
+			return nil, fmt.Errorf("watermark_opacity must be a number")
+		}
+	}
+	if w.Tile, err = parseBool(ctx, "watermark_tile"); err != nil {
+		return nil, err
+	}
+	if v := ctx.PostForm("watermark_margin"); v != "" {
+		if w.Margin, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("watermark_margin must be an integer")
+		}
+	}
+	if v := ctx.PostForm("watermark_font_size"); v != "" {
+		if w.FontSize, err = strconv.Atoi(v); err != nil {
+			return nil, fmt.Errorf("watermark_font_size must be an integer")
+		}
+	}
+	return &w, nil
+}
+
+// watermark водяной знак запроса. Если у ключа нет знака, запрос может задать свой;
+// заменить или отключить знак ключа можно только с разрешением watermark
+func (h *Handler) watermark(ctx *gin.Context, requested *service.Watermark, disable bool) (*service.Watermark, error) {
+	cfg := h.cfg()
+	key := middleware.APIKey(ctx)
+	keyWatermark := options.Watermark(cfg.KeyWatermark(key))
+	if requested == nil && !disable {
+		return keyWatermark, nil
+	}
+	if keyWatermark != nil && !key.HasScope(config.ScopeWatermark) {
+		return nil, errWatermarkScope
+	}
+	if disable {
+		return nil, nil
+	}
+
+	base := keyWatermark
+	if base == nil {
+		style := cfg.WatermarkStyle()
+		base = options.Watermark(&style)
+	}
+	w := base.Override(*requested)
+	return w, w.Validate()
+}
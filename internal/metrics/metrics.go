@@ -0,0 +1,76 @@
+package metrics
+
+import "github.com/prometheus/client_golang/prometheus"
+
+// метрики для prometheus, общие для HTTP и gRPC API
+var (
+	ActiveWorkers = prometheus.NewGauge(prometheus.GaugeOpts{
+		Name: "screenshot_service_active_workers",
+		Help: "Current number of active screenshot workers",
+	})
+
+	TotalRequests = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "screenshot_service_requests_total",
+			Help: "Total number of screenshot requests",
+		},
+		[]string{"status"},
+	)
+
+	// RequestDuration длительность запросов, включая завершившиеся ошибкой
+	RequestDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "screenshot_service_request_duration_seconds",
+			Help:    "Duration of screenshot requests",
+			Buckets: []float64{0.1, 0.5, 1, 2, 5, 10, 30},
+		},
+		[]string{"browser", "type", "status"},
+	)
+
+	// PhaseDuration длительность этапов рендера: launch, navigate, wait, capture, encode
+	PhaseDuration = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "screenshot_service_phase_duration_seconds",
+			Help:    "Duration of screenshot rendering phases",
+			Buckets: []float64{0.01, 0.05, 0.1, 0.25, 0.5, 1, 2, 5, 10},
+		},
+		[]string{"phase", "browser"},
+	)
+
+	QueueWait = prometheus.NewHistogram(
+		prometheus.HistogramOpts{
+			Name:    "screenshot_service_queue_wait_seconds",
+			Help:    "Time spent waiting for a free screenshot worker",
+			Buckets: []float64{0.001, 0.01, 0.1, 0.5, 1, 5},
+		},
+	)
+
+	OutputSize = prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Name:    "screenshot_service_output_size_bytes",
+			Help:    "Size of produced screenshots",
+			Buckets: prometheus.ExponentialBuckets(16<<10, 2, 10), // 16Kb..8Mb
+		},
+		[]string{"type"},
+	)
+
+	// BrowserCrashes падения страницы (page_crash) и неожиданные отключения браузера (disconnected)
+	BrowserCrashes = prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Name: "screenshot_service_browser_crashes_total",
+			Help: "Total number of browser and page crashes",
+		},
+		[]string{"browser", "reason"},
+	)
+)
+
+func init() {
+	// Регистрируем метрики
+	prometheus.MustRegister(ActiveWorkers)
+	prometheus.MustRegister(TotalRequests)
+	prometheus.MustRegister(RequestDuration)
+	prometheus.MustRegister(PhaseDuration)
+	prometheus.MustRegister(QueueWait)
+	prometheus.MustRegister(OutputSize)
+	prometheus.MustRegister(BrowserCrashes)
+}
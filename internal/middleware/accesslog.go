@@ -0,0 +1,57 @@
+package middleware
+
+import (
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"screenshoter/pkg/logger"
+	"time"
+
+	"github.com/rs/zerolog"
+)
+
+// Ключи gin.Context с параметрами рендера для журнала запросов, задаются обработчиком
+const (
+	BrowserContextKey  = "render_browser"
+	TypeContextKey     = "render_type"
+	CacheHitContextKey = "render_cache_hit" // Ответ без рендера по If-None-Match
+)
+
+// AccessLogMiddleware пишет одну структурированную строку на каждый запрос.
+// Должен подключаться после RequestIDMiddleware
+func AccessLogMiddleware(lgr *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		start := time.Now()
+
+		c.Next()
+
+		status := c.Writer.Status()
+		reqLogger := logger.FromContext(c.Request.Context(), lgr)
+		var event *zerolog.Event
+		if status >= http.StatusInternalServerError {
+			event = reqLogger.Warn()
+		} else {
+			event = reqLogger.Info()
+		}
+
+		event = event.
+			Str("method", c.Request.Method).
+			Str("path", c.Request.URL.Path).
+			Int("status", status).
+			Dur("duration", time.Since(start)).
+			Int("bytes", max(c.Writer.Size(), 0)).
+			Str("client_ip", c.ClientIP())
+		if key := APIKey(c); key != nil {
+			event = event.Str("api_key", key.Name)
+		}
+		if browser := c.GetString(BrowserContextKey); browser != "" {
+			event = event.Str("browser", browser)
+		}
+		if outputType := c.GetString(TypeContextKey); outputType != "" {
+			event = event.Str("type", outputType)
+		}
+		if hit, ok := c.Get(CacheHitContextKey); ok {
+			event = event.Bool("cache_hit", hit.(bool))
+		}
+		event.Msg("request")
+	}
+}
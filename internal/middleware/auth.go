@@ -7,7 +7,10 @@ import (
 	"strings"
 )
 
-func BearerAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
+// APIKeyContextKey ключ gin.Context, под которым хранится ключ доступа запроса
+const APIKeyContextKey = "api_key"
+
+func BearerAuthMiddleware(cfg *config.Manager) gin.HandlerFunc {
 	return func(c *gin.Context) {
 		authHeader := c.GetHeader("Authorization")
 		if authHeader == "" {
@@ -21,17 +24,22 @@ func BearerAuthMiddleware(cfg *config.Config) gin.HandlerFunc {
 			return
 		}
 
-		token := parts[1]
-		if !isValidToken(token, cfg) {
+		key, ok := cfg.Get().LookupKey(parts[1])
+		if !ok {
 			c.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"error": "Invalid token"})
 			return
 		}
+		c.Set(APIKeyContextKey, key)
+		SetSentryTag(c, "api_key", key.Name)
 
 		c.Next()
 	}
 
 }
 
-func isValidToken(token string, cfg *config.Config) bool {
-	return token == cfg.AccessToken
+// APIKey ключ доступа, с которым выполнен запрос
+func APIKey(c *gin.Context) *config.APIKey {
+	key, _ := c.Get(APIKeyContextKey)
+	apiKey, _ := key.(*config.APIKey)
+	return apiKey
 }
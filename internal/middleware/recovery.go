@@ -0,0 +1,65 @@
+package middleware
+
+import (
+	"errors"
+	"github.com/getsentry/sentry-go"
+	"github.com/gin-gonic/gin"
+	"net/http"
+	"screenshoter/internal/metrics"
+	"screenshoter/pkg/logger"
+)
+
+// RecoveryMiddleware создает hub Sentry для запроса и перехватывает панику обработчиков:
+// пишет ошибку со стеком в лог (и Sentry) и отвечает 500.
+// Должен подключаться после RequestIDMiddleware и AccessLogMiddleware, чтобы запрос с паникой
+// попал в журнал с кодом 500 и идентификатором запроса
+func RecoveryMiddleware(lgr *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		hub := sentry.CurrentHub().Clone()
+		hub.Scope().SetRequest(c.Request)
+		if id := RequestID(c); id != "" {
+			hub.Scope().SetTag("request_id", id)
+		}
+		ctx := sentry.SetHubOnContext(c.Request.Context(), hub)
+		c.Request = c.Request.WithContext(logger.Rebind(ctx, lgr))
+
+		defer func() {
+			rec := recover()
+			if rec == nil {
+				return
+			}
+			// Клиент оборвал соединение, net/http обрабатывает это сам
+			if err, ok := rec.(error); ok && errors.Is(err, http.ErrAbortHandler) {
+				panic(rec)
+			}
+
+			panicErr := logger.NewPanicError(rec)
+			logger.FromContext(c.Request.Context(), lgr).Exception(panicErr).
+				Str("method", c.Request.Method).
+				Str("path", c.Request.URL.Path).
+				Msg("Panic recovered")
+
+			metrics.TotalRequests.WithLabelValues("500").Inc()
+			c.AbortWithStatusJSON(http.StatusInternalServerError, gin.H{
+				"message":    "internal server error",
+				"request_id": RequestID(c),
+			})
+		}()
+
+		c.Next()
+	}
+}
+
+// SetSentryTag добавляет тег к событиям Sentry текущего запроса
+func SetSentryTag(c *gin.Context, key, value string) {
+	if hub := sentry.GetHubFromContext(c.Request.Context()); hub != nil {
+		hub.Scope().SetTag(key, value)
+	}
+}
+
+// SetSentryContext добавляет именованный контекст к событиям Sentry текущего запроса
+func SetSentryContext(c *gin.Context, key string, value sentry.Context) {
+	if hub := sentry.GetHubFromContext(c.Request.Context()); hub != nil {
+		hub.Scope().SetContext(key, value)
+	}
+}
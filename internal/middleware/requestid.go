@@ -0,0 +1,71 @@
+package middleware
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"github.com/gin-gonic/gin"
+	"screenshoter/pkg/logger"
+
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// RequestIDHeader заголовок с идентификатором запроса
+const RequestIDHeader = "X-Request-ID"
+
+// RequestIDContextKey ключ gin.Context, под которым хранится идентификатор запроса
+const RequestIDContextKey = "request_id"
+
+// maxRequestIDLength максимальная длина идентификатора, принимаемого от клиента
+const maxRequestIDLength = 128
+
+// RequestIDMiddleware берет X-Request-ID из запроса или создает новый, возвращает его в ответе
+// и добавляет в контекст запроса логгер с полем request_id
+func RequestIDMiddleware(lgr *logger.Logger) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		id := EnsureRequestID(c.GetHeader(RequestIDHeader))
+		c.Set(RequestIDContextKey, id)
+		c.Header(RequestIDHeader, id)
+
+		ctx := logger.WithRequestID(c.Request.Context(), id)
+		reqLogger := &logger.Logger{Logger: lgr.With().Str("request_id", id).Ctx(ctx).Logger()}
+		ctx = logger.NewContext(ctx, reqLogger)
+		c.Request = c.Request.WithContext(ctx)
+
+		trace.SpanFromContext(ctx).SetAttributes(attribute.String("request.id", id))
+
+		c.Next()
+	}
+}
+
+// RequestID идентификатор текущего запроса
+func RequestID(c *gin.Context) string {
+	return c.GetString(RequestIDContextKey)
+}
+
+// EnsureRequestID идентификатор клиента, если он корректен, иначе новый
+func EnsureRequestID(id string) string {
+	if !validRequestID(id) {
+		return newRequestID()
+	}
+	return id
+}
+
+// validRequestID принимаем от клиента только непустые идентификаторы из печатных ASCII-символов
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func newRequestID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+	return hex.EncodeToString(b)
+}
@@ -0,0 +1,86 @@
+package options
+
+import (
+	"screenshoter/config"
+	"screenshoter/internal/service"
+)
+
+// Limits ограничения параметров скриншота из конфигурации
+func Limits(cfg *config.Config) service.Limits {
+	limits := service.Limits{
+		MaxViewportWidth:  cfg.MaxViewportWidth,
+		MaxViewportHeight: cfg.MaxViewportHeight,
+		MaxFullPageHeight: cfg.MaxFullPageHeight,
+		MaxTimeout:        float64(cfg.MaxTimeout),
+		AllowedTypes:      cfg.AllowedTypes,
+		MaxActions:        cfg.MaxActions,
+		MaxScrollSteps:    cfg.MaxScrollSteps,
+
+		MaxAnimationDuration: float64(cfg.MaxAnimationDuration),
+		MaxFrameRate:         cfg.MaxFrameRate,
+		MaxAnimationSize:     cfg.MaxAnimationSize,
+	}
+	for _, b := range cfg.AllowedBrowsers {
+		limits.AllowedBrowsers = append(limits.AllowedBrowsers, service.BrowserType(b))
+	}
+	limits.LaunchPresets = make(map[string]service.LaunchPreset, len(cfg.LaunchPresets))
+	for _, p := range cfg.LaunchPresets {
+		limits.LaunchPresets[p.Name] = LaunchPreset(p)
+	}
+	limits.DefaultLaunchPreset = cfg.LaunchPreset
+	return limits
+}
+
+// LaunchPreset пресет запуска из конфигурации
+func LaunchPreset(c config.LaunchPreset) service.LaunchPreset {
+	return service.LaunchPreset{
+		Browser:            service.BrowserType(c.Browser),
+		Channel:            c.Channel,
+		ExecutablePath:     c.ExecutablePath,
+		Args:               c.Args,
+		Proxy:              Proxy(c.Proxy),
+		IgnoreHTTPSErrors:  c.IgnoreHTTPSErrors,
+		JavaScriptDisabled: c.JavaScriptEnabled != nil && !*c.JavaScriptEnabled,
+	}
+}
+
+// Proxy прокси из конфигурации, nil - без прокси
+func Proxy(c *config.Proxy) *service.Proxy {
+	if c == nil {
+		return nil
+	}
+	return &service.Proxy{Server: c.Server, Bypass: c.Bypass, Username: c.Username, Password: service.Secret(c.Password)}
+}
+
+// Watermark водяной знак из конфигурации с PNG, прочитанным при ее загрузке; nil - без знака
+func Watermark(c *config.Watermark) *service.Watermark {
+	if c == nil {
+		return nil
+	}
+	return &service.Watermark{Text: c.Text, Image: c.Data, Position: c.Position, Opacity: c.Opacity,
+		Tile: c.Tile != nil && *c.Tile, Margin: c.Margin, Color: c.Color, FontSize: c.FontSize}
+}
+
+// Emulation эмуляция настроек пользователя по умолчанию из конфигурации
+func Emulation(cfg *config.Config) service.Emulation {
+	e := service.Emulation{
+		ColorScheme:    cfg.ColorScheme,
+		ReducedMotion:  cfg.ReducedMotion,
+		Locale:         cfg.Locale,
+		TimezoneID:     cfg.TimezoneID,
+		AcceptLanguage: cfg.AcceptLanguage,
+		Media:          cfg.Media,
+	}
+	// Координаты проверены при загрузке конфигурации
+	if cfg.Geolocation != "" {
+		if g, err := config.ParseGeolocation(cfg.Geolocation); err == nil {
+			e.Geolocation = Geolocation(g)
+		}
+	}
+	return e
+}
+
+// Geolocation координаты из конфигурации
+func Geolocation(g *config.Geolocation) *service.Geolocation {
+	return &service.Geolocation{Latitude: g.Latitude, Longitude: g.Longitude, Accuracy: g.Accuracy}
+}
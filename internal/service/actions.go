@@ -0,0 +1,179 @@
+package service
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Действия со страницей перед скриншотом
+const (
+	ActionClick          = "click"
+	ActionHover          = "hover"
+	ActionFill           = "fill"
+	ActionPress          = "press"
+	ActionSelectOption   = "select_option"
+	ActionScrollIntoView = "scroll_into_view"
+	ActionScroll         = "scroll"
+	ActionWait           = "wait"
+	ActionEvaluate       = "evaluate"
+)
+
+// DefaultActionTimeout таймаут действия, если он не задан
+const DefaultActionTimeout = 5000.0
+
+// Action действие со страницей, выполняется после загрузки до скриншота
+type Action struct {
+	Type     string   `json:"type"`
+	Selector string   `json:"selector,omitempty"` // CSS или другой селектор playwright
+	Value    string   `json:"value,omitempty"`    // Текст для fill, клавиша для press
+	Values   []string `json:"values,omitempty"`   // Значения или подписи для select_option
+	Script   string   `json:"script,omitempty"`   // JavaScript для evaluate
+	X        int      `json:"x,omitempty"`        // Координаты для scroll
+	Y        int      `json:"y,omitempty"`
+	Duration float64  `json:"duration,omitempty"` // Пауза wait без селектора (мс)
+	Timeout  float64  `json:"timeout,omitempty"`  // Таймаут ожидания элемента (мс), по умолчанию DefaultActionTimeout
+}
+
+// ActionError действие со страницей не выполнено
+type ActionError struct {
+	Index    int    `json:"index"` // Номер действия, с нуля
+	Type     string `json:"type"`
+	Selector string `json:"selector,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (e *ActionError) Error() string {
+	if e.Selector != "" {
+		return fmt.Sprintf("action %d (%s %q) failed: %s", e.Index, e.Type, e.Selector, e.Message)
+	}
+	return fmt.Sprintf("action %d (%s) failed: %s", e.Index, e.Type, e.Message)
+}
+
+// ValidateActions проверяет, что у каждого действия есть нужные параметры
+func ValidateActions(actions []Action) error {
+	for i, a := range actions {
+		invalid := func(format string, args ...any) error {
+			return &ActionError{Index: i, Type: a.Type, Selector: a.Selector, Message: fmt.Sprintf(format, args...)}
+		}
+		switch a.Type {
+		case ActionClick, ActionHover, ActionFill, ActionScrollIntoView:
+			if a.Selector == "" {
+				return invalid("selector is required")
+			}
+		case ActionPress:
+			if a.Value == "" {
+				return invalid("value (key) is required")
+			}
+		case ActionSelectOption:
+			if a.Selector == "" || len(a.Values) == 0 {
+				return invalid("selector and values are required")
+			}
+		case ActionWait:
+			if a.Selector == "" && a.Duration <= 0 {
+				return invalid("selector or duration is required")
+			}
+		case ActionEvaluate:
+			if a.Script == "" {
+				return invalid("script is required")
+			}
+		case ActionScroll:
+		default:
+			return invalid("unknown action type")
+		}
+		if a.Timeout < 0 || a.Duration < 0 {
+			return invalid("timeout and duration must not be negative")
+		}
+	}
+	return nil
+}
+
+// actions действия запроса; прокрутка ScrollX/ScrollY выполняется первой
+func (o ScreenshotOptions) actions() []Action {
+	if o.ScrollX == 0 && o.ScrollY == 0 {
+		return o.Actions
+	}
+	return append([]Action{{Type: ActionScroll, X: o.ScrollX, Y: o.ScrollY}}, o.Actions...)
+}
+
+// actionsDuration наибольшее время выполнения действий (мс)
+func actionsDuration(actions []Action) float64 {
+	var total float64
+	for _, a := range actions {
+		total += a.timeout() + a.Duration
+	}
+	return total
+}
+
+func (a Action) timeout() float64 {
+	if a.Timeout > 0 {
+		return a.Timeout
+	}
+	return DefaultActionTimeout
+}
+
+// runActions выполняет действия по порядку, каждое в своем спане
+func runActions(ctx context.Context, page playwright.Page, actions []Action) error {
+	for i, a := range actions {
+		_, span := tracer.Start(ctx, "page.action", trace.WithAttributes(
+			attribute.Int("action.index", i),
+			attribute.String("action.type", a.Type),
+		))
+		err := runAction(page, a)
+		endSpan(span, err)
+		if err != nil {
+			return &ActionError{Index: i, Type: a.Type, Selector: a.Selector, Message: err.Error()}
+		}
+	}
+	return nil
+}
+
+func runAction(page playwright.Page, a Action) error {
+	timeout := playwright.Float(a.timeout())
+	locator := func() playwright.Locator { return page.Locator(a.Selector) }
+
+	switch a.Type {
+	case ActionClick:
+		return locator().Click(playwright.LocatorClickOptions{Timeout: timeout})
+	case ActionHover:
+		return locator().Hover(playwright.LocatorHoverOptions{Timeout: timeout})
+	case ActionFill:
+		return locator().Fill(a.Value, playwright.LocatorFillOptions{Timeout: timeout})
+	case ActionPress:
+		if a.Selector == "" {
+			return page.Keyboard().Press(a.Value)
+		}
+		return locator().Press(a.Value, playwright.LocatorPressOptions{Timeout: timeout})
+	case ActionSelectOption:
+		_, err := locator().SelectOption(playwright.SelectOptionValues{ValuesOrLabels: &a.Values},
+			playwright.LocatorSelectOptionOptions{Timeout: timeout})
+		return err
+	case ActionScrollIntoView:
+		return locator().ScrollIntoViewIfNeeded(playwright.LocatorScrollIntoViewIfNeededOptions{Timeout: timeout})
+	case ActionScroll:
+		if _, err := page.Evaluate("([x, y]) => window.scrollTo(x, y)", []int{a.X, a.Y}); err != nil {
+			return err
+		}
+		// Ждем завершения прокрутки
+		time.Sleep(100 * time.Millisecond)
+		return nil
+	case ActionWait:
+		if a.Selector != "" {
+			if err := locator().WaitFor(playwright.LocatorWaitForOptions{Timeout: timeout}); err != nil {
+				return err
+			}
+		}
+		if a.Duration > 0 {
+			page.WaitForTimeout(a.Duration)
+		}
+		return nil
+	case ActionEvaluate:
+		_, err := page.Evaluate(a.Script)
+		return err
+	}
+	return fmt.Errorf("unknown action type")
+}
@@ -0,0 +1,251 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	"image/gif"
+	"image/png"
+	"os"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Анимация страницы: gif из периодических скриншотов окна или webm из видеозаписи playwright
+const (
+	TypeGIF  = "gif"
+	TypeWebM = "webm"
+)
+
+// Значения анимации по умолчанию
+const (
+	DefaultAnimationDuration = 3000 // мс
+	DefaultFrameRate         = 10   // кадров в секунду
+)
+
+// defaultVideoSize размер видео, если viewport не задан, совпадает с окном playwright по умолчанию
+var defaultVideoSize = playwright.Size{Width: DefaultViewportWidth, Height: DefaultViewportHeight}
+
+// Animated записывается ли анимация вместо скриншота
+func (o ScreenshotOptions) Animated() bool {
+	return o.Type == TypeGIF || o.Type == TypeWebM
+}
+
+// animationDuration длительность записи (мс) с учетом значения по умолчанию
+func (o ScreenshotOptions) animationDuration() float64 {
+	if !o.Animated() {
+		return 0
+	}
+	if o.Duration > 0 {
+		return o.Duration
+	}
+	return DefaultAnimationDuration
+}
+
+// frameRate частота кадров gif с учетом значения по умолчанию
+func (o ScreenshotOptions) frameRate() int {
+	if o.FrameRate > 0 {
+		return o.FrameRate
+	}
+	return DefaultFrameRate
+}
+
+// ValidateAnimation проверяет длительность и частоту кадров анимации
+func ValidateAnimation(opts ScreenshotOptions) error {
+	if !opts.Animated() {
+		return nil
+	}
+	if opts.Duration < 0 || opts.FrameRate < 0 {
+		return fmt.Errorf("duration and frame_rate must not be negative")
+	}
+	if opts.ScrollContainer != "" {
+		return fmt.Errorf("scroll_container is not supported for %s", opts.Type)
+	}
+	return nil
+}
+
+// captureGIF снимает окно страницы с частотой frameRate в течение animationDuration.
+// Задержка кадра равна фактическому времени между снимками, поэтому медленный рендер не ускоряет анимацию
+func captureGIF(ctx context.Context, page playwright.Page, opts ScreenshotOptions) ([]byte, error) {
+	interval := time.Second / time.Duration(opts.frameRate())
+	duration := time.Duration(opts.animationDuration()) * time.Millisecond
+
+	anim := &gif.GIF{}
+	start := time.Now()
+	last := start
+	for next := start; ; next = next.Add(interval) {
+		if err := sleep(ctx, time.Until(next)); err != nil {
+			return nil, err
+		}
+		data, err := page.Screenshot(playwright.PageScreenshotOptions{
+			Type:           playwright.ScreenshotTypePng,
+			OmitBackground: playwright.Bool(opts.OmitBackground),
+		})
+		if err != nil {
+			return nil, fmt.Errorf("failed to capture frame %d: %w", len(anim.Image), err)
+		}
+		frame, err := png.Decode(bytes.NewReader(data))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode frame %d: %w", len(anim.Image), err)
+		}
+
+		now := time.Now()
+		if n := len(anim.Delay); n > 0 {
+			anim.Delay[n-1] = max(1, int(now.Sub(last)/(10*time.Millisecond)))
+		}
+		last = now
+		paletted, err := gifFrame(frame, opts.Watermark)
+		if err != nil {
+			return nil, err
+		}
+		anim.Image = append(anim.Image, paletted)
+		anim.Delay = append(anim.Delay, int(interval/(10*time.Millisecond)))
+
+		if now.Add(interval).Sub(start) > duration {
+			break
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := gif.EncodeAll(&buf, anim); err != nil {
+		return nil, fmt.Errorf("failed to encode gif: %w", err)
+	}
+	return buf.Bytes(), nil
+}
+
+// gifFrame кадр gif с водяным знаком в палитре Plan 9 с дизерингом
+func gifFrame(frame image.Image, watermark *Watermark) (*image.Paletted, error) {
+	if watermark != nil {
+		rgba := image.NewRGBA(frame.Bounds())
+		draw.Draw(rgba, rgba.Bounds(), frame, frame.Bounds().Min, draw.Src)
+		if err := drawWatermark(rgba, watermark); err != nil {
+			return nil, err
+		}
+		frame = rgba
+	}
+	paletted := image.NewPaletted(frame.Bounds(), palette.Plan9)
+	draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), frame, frame.Bounds().Min)
+	return paletted, nil
+}
+
+// videoSize размер webm по размеру окна, иначе playwright уменьшит видео до 800x800
+func videoSize(opts ScreenshotOptions) *playwright.Size {
+	if opts.Viewport != nil {
+		return &playwright.Size{Width: opts.Viewport.Width, Height: opts.Viewport.Height}
+	}
+	size := defaultVideoSize
+	return &size
+}
+
+// recordWebM ждет animationDuration и возвращает видео страницы с момента ее открытия.
+// Страница закрывается: playwright дописывает файл видео только после закрытия
+func recordWebM(ctx context.Context, page playwright.Page, opts ScreenshotOptions) ([]byte, error) {
+	if err := sleep(ctx, time.Duration(opts.animationDuration())*time.Millisecond); err != nil {
+		return nil, err
+	}
+	video := page.Video()
+	if video == nil {
+		return nil, fmt.Errorf("video recording is not enabled")
+	}
+	if err := page.Close(); err != nil {
+		return nil, fmt.Errorf("failed to finish video: %w", err)
+	}
+	path, err := video.Path()
+	if err != nil {
+		return nil, fmt.Errorf("failed to get video path: %w", err)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read video: %w", err)
+	}
+	return data, nil
+}
+
+// watermarkInitScript скрипт, который добавляет водяной знак в каждый документ видеозаписи.
+// Знак рисуется тем же кодом, что и для изображений, и вставляется как PNG
+func watermarkInitScript(w *Watermark) (string, error) {
+	mark, err := w.render()
+	if err != nil {
+		return "", err
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, mark); err != nil {
+		return "", fmt.Errorf("failed to encode watermark: %w", err)
+	}
+	opacity := w.Opacity
+	if opacity == 0 {
+		opacity = DefaultWatermarkOpacity
+	}
+
+	// Положение знака в CSS
+	m := fmt.Sprintf("%dpx", w.Margin)
+	style := map[string]string{}
+	switch {
+	case w.Tile:
+		size := mark.Bounds().Size()
+		style["inset"] = "0"
+		style["backgroundRepeat"] = "repeat"
+		style["backgroundPosition"] = m + " " + m
+		style["backgroundSize"] = fmt.Sprintf("%dpx %dpx", size.X+max(w.Margin, size.Y), size.Y+max(w.Margin, size.Y))
+	case w.Position == WatermarkTopLeft:
+		style["top"], style["left"] = m, m
+	case w.Position == WatermarkTopRight:
+		style["top"], style["right"] = m, m
+	case w.Position == WatermarkBottomLeft:
+		style["bottom"], style["left"] = m, m
+	case w.Position == WatermarkCenter:
+		style["top"], style["left"], style["transform"] = "50%", "50%", "translate(-50%, -50%)"
+	default:
+		style["bottom"], style["right"] = m, m
+	}
+
+	styleJSON, err := json.Marshal(style)
+	if err != nil {
+		return "", err
+	}
+	return fmt.Sprintf(`(() => {
+	const src = 'data:image/png;base64,%s';
+	const style = %s;
+	const add = () => {
+		const mark = document.createElement(style.inset ? 'div' : 'img');
+		if (style.inset) {
+			mark.style.backgroundImage = 'url(' + src + ')';
+		} else {
+			mark.src = src;
+		}
+		Object.assign(mark.style, {position: 'fixed', zIndex: '2147483647', pointerEvents: 'none', opacity: '%v'}, style);
+		document.documentElement.appendChild(mark);
+	};
+	if (document.documentElement) {
+		add();
+	} else {
+		new MutationObserver((_, observer) => {
+			if (document.documentElement) {
+				observer.disconnect();
+				add();
+			}
+		}).observe(document, {childList: true});
+	}
+})();`, base64.StdEncoding.EncodeToString(buf.Bytes()), styleJSON, opacity), nil
+}
+
+// sleep ждет d или отмены ctx
+func sleep(ctx context.Context, d time.Duration) error {
+	if d <= 0 {
+		return ctx.Err()
+	}
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
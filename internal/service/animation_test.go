@@ -0,0 +1,91 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/gif"
+	"screenshoter/config"
+	"screenshoter/pkg/logger"
+	"strings"
+	"testing"
+)
+
+// isRed цвет рамки выделения после перевода кадра в палитру gif
+func isRed(c color.Color) bool {
+	r, g, b, _ := c.RGBA()
+	return r>>8 > 200 && g>>8 < 60 && b>>8 < 60
+}
+
+func TestGIFFrameKeepsSelection(t *testing.T) {
+	frame := image.NewRGBA(image.Rect(0, 0, 60, 40))
+	draw.Draw(frame, frame.Bounds(), image.White, image.Point{}, draw.Src)
+	style := &SelectionStyle{BorderColor: "#FF0000", BorderWidth: 2, BorderStyle: "solid", Opacity: 1}
+	if err := drawSelection(frame, image.Rect(10, 10, 50, 30), style); err != nil {
+		t.Fatal(err)
+	}
+
+	paletted, err := gifFrame(frame, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isRed(paletted.At(10, 20)) || !isRed(paletted.At(30, 10)) {
+		t.Errorf("selection border is lost in the gif frame: %v", paletted.At(10, 20))
+	}
+	if isRed(paletted.At(30, 20)) {
+		t.Errorf("selection is filled in the gif frame")
+	}
+}
+
+func TestGIFWithSelections(t *testing.T) {
+	opts := ScreenshotOptions{
+		Browser:   BrowserChromium,
+		Type:      TypeGIF,
+		Duration:  300,
+		FrameRate: 5,
+		Timeout:   10000,
+		Selections: []SelectionArea{
+			{X: 10, Y: 10, Width: 100, Height: 50},
+			{Space: SelectionElement, Selector: "#box"},
+		},
+		SelectionStyle: &SelectionStyle{BorderColor: "#FF0000", BorderWidth: 4, BorderStyle: "solid", Opacity: 1},
+	}
+	if err := ValidateAnimation(opts); err != nil {
+		t.Fatal(err)
+	}
+	if err := ValidateSelections(opts.Selections); err != nil {
+		t.Fatal(err)
+	}
+
+	pw, err := NewPlaywright(logger.NewLogger(&config.Config{}))
+	if err != nil {
+		t.Skipf("playwright is not installed: %v", err)
+	}
+	defer pw.Close()
+
+	html := `<body style="margin:0;background:#fff"><div id="box" style="position:absolute;left:200px;top:100px;width:80px;height:40px"></div></body>`
+	res, err := pw.Make(context.Background(), html, opts)
+	if err != nil && strings.Contains(err.Error(), "could not launch") {
+		t.Skipf("browser is not installed: %v", err)
+	}
+	if err != nil {
+		t.Fatal(err)
+	}
+	anim, err := gif.DecodeAll(bytes.NewReader(res.Image))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(anim.Image) < 2 {
+		t.Fatalf("gif has %d frames, want at least 2", len(anim.Image))
+	}
+	for i, frame := range anim.Image {
+		if !isRed(frame.At(11, 30)) {
+			t.Errorf("frame %d: page selection is not drawn", i)
+		}
+		if !isRed(frame.At(201, 120)) {
+			t.Errorf("frame %d: element selection is not drawn", i)
+		}
+	}
+}
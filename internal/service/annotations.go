@@ -0,0 +1,272 @@
+package service
+
+import (
+	"fmt"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Типы аннотаций
+const (
+	AnnotationArrow   = "arrow"   // Стрелка из X, Y в X2, Y2
+	AnnotationCallout = "callout" // Выноска с текстом, левый верхний угол в X, Y
+	AnnotationMarker  = "marker"  // Номер шага в круге с центром в X, Y
+)
+
+// DefaultSpotlightOpacity непрозрачность затемнения, если она не задана
+const DefaultSpotlightOpacity = 0.6
+
+// defaultAnnotationStyle стиль аннотаций, если он не задан
+var defaultAnnotationStyle = AnnotationStyle{
+	Color:       "red",
+	TextColor:   "white",
+	FontSize:    14,
+	StrokeWidth: 3,
+}
+
+// AnnotationStyle стиль аннотации, незаданные поля берутся из стиля по умолчанию
+type AnnotationStyle struct {
+	Color       string `json:"color,omitempty"`        // Цвет стрелки, фона выноски и маркера (CSS-формат)
+	TextColor   string `json:"text_color,omitempty"`   // Цвет текста выноски и маркера
+	FontSize    int    `json:"font_size,omitempty"`    // Размер шрифта (px), от него зависит размер маркера
+	StrokeWidth int    `json:"stroke_width,omitempty"` // Толщина линии стрелки (px)
+}
+
+// merge стиль s, дополненный заданными полями o
+func (s AnnotationStyle) merge(o *AnnotationStyle) AnnotationStyle {
+	if o == nil {
+		return s
+	}
+	if o.Color != "" {
+		s.Color = o.Color
+	}
+	if o.TextColor != "" {
+		s.TextColor = o.TextColor
+	}
+	if o.FontSize > 0 {
+		s.FontSize = o.FontSize
+	}
+	if o.StrokeWidth > 0 {
+		s.StrokeWidth = o.StrokeWidth
+	}
+	return s
+}
+
+// Annotation аннотация поверх страницы. Координаты задаются так же, как у SelectionArea
+type Annotation struct {
+	Type     string           `json:"type"`               // arrow, callout или marker
+	X        int              `json:"x"`                  // Начало стрелки, угол выноски или центр маркера
+	Y        int              `json:"y"`                  //
+	X2       int              `json:"x2,omitempty"`       // Конец стрелки
+	Y2       int              `json:"y2,omitempty"`       //
+	Text     string           `json:"text,omitempty"`     // Текст выноски или маркера, у маркера по умолчанию - его номер
+	Space    string           `json:"space,omitempty"`    // Система координат: page, viewport или element
+	Selector string           `json:"selector,omitempty"` // CSS-селектор элемента для element
+	Style    *AnnotationStyle `json:"style,omitempty"`    // Стиль этой аннотации
+}
+
+// AnnotationError аннотацию нельзя нарисовать
+type AnnotationError struct {
+	Index    int    `json:"index"` // Номер аннотации, с нуля
+	Type     string `json:"type"`
+	Selector string `json:"selector,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (e *AnnotationError) Error() string {
+	return fmt.Sprintf("annotation %d (%s): %s", e.Index, e.Type, e.Message)
+}
+
+// ValidateAnnotations проверяет аннотации и затемнение
+func ValidateAnnotations(opts ScreenshotOptions) error {
+	for i, a := range opts.Annotations {
+		fail := func(message string) error {
+			return &AnnotationError{Index: i, Type: a.Type, Selector: a.Selector, Message: message}
+		}
+		switch a.Type {
+		case AnnotationArrow:
+			if a.X == a.X2 && a.Y == a.Y2 {
+				return fail("arrow end x2, y2 must differ from its start")
+			}
+		case AnnotationCallout:
+			if a.Text == "" {
+				return fail("text is required")
+			}
+		case AnnotationMarker:
+		default:
+			return fail("type must be arrow, callout or marker")
+		}
+		switch a.Space {
+		case "", SelectionPage, SelectionViewport:
+		case SelectionElement:
+			if a.Selector == "" {
+				return fail("selector is required for element space")
+			}
+		default:
+			return fail("space must be page, viewport or element")
+		}
+		if a.Style != nil && (a.Style.FontSize < 0 || a.Style.StrokeWidth < 0) {
+			return fail("font_size and stroke_width must not be negative")
+		}
+	}
+	if len(opts.Annotations) > 0 && opts.ScrollContainer != "" {
+		return fmt.Errorf("annotations are not supported with scroll_container")
+	}
+	if opts.Spotlight && len(opts.Selections) == 0 {
+		return fmt.Errorf("spotlight requires selections")
+	}
+	if opts.SpotlightOpacity < 0 || opts.SpotlightOpacity > 1 {
+		return fmt.Errorf("spotlight_opacity must be between 0 and 1")
+	}
+	return nil
+}
+
+// spotlightOpacity непрозрачность затемнения с учетом значения по умолчанию
+func (o ScreenshotOptions) spotlightOpacity() float64 {
+	if o.SpotlightOpacity > 0 {
+		return o.SpotlightOpacity
+	}
+	return DefaultSpotlightOpacity
+}
+
+// drawAnnotationsJS добавляет поверх страницы svg-слой с затемнением вне holes и стрелками,
+// затем выноски и маркеры. Возвращает notFound с номером аннотации, если ее элемент не найден
+const drawAnnotationsJS = `({annotations, spotlight, holes}) => {
+	` + locateJS + `
+	const root = document.documentElement;
+	const body = document.body;
+	const width = Math.max(root.scrollWidth, body ? body.scrollWidth : 0, window.innerWidth);
+	const height = Math.max(root.scrollHeight, body ? body.scrollHeight : 0, window.innerHeight);
+	const ns = 'http://www.w3.org/2000/svg';
+	const node = (name, attrs, parent) => {
+		const el = document.createElementNS(ns, name);
+		for (const key in attrs) {
+			el.setAttribute(key, attrs[key]);
+		}
+		parent.appendChild(el);
+		return el;
+	};
+	const overlay = (el, style) => {
+		Object.assign(el.style, {position: 'absolute', zIndex: '2147483647', pointerEvents: 'none'}, style);
+		root.appendChild(el);
+	};
+
+	const svg = document.createElementNS(ns, 'svg');
+	svg.id = 'screenshot-annotations';
+	svg.setAttribute('width', width);
+	svg.setAttribute('height', height);
+	overlay(svg, {left: '0', top: '0', overflow: 'visible'});
+	const defs = node('defs', {}, svg);
+
+	if (spotlight > 0) {
+		const mask = node('mask', {id: 'screenshot-spotlight'}, defs);
+		node('rect', {x: 0, y: 0, width, height, fill: 'white'}, mask);
+		holes.forEach(h => node('rect', {x: h.x, y: h.y, width: h.width, height: h.height, fill: 'black'}, mask));
+		node('rect', {x: 0, y: 0, width, height, fill: 'black', 'fill-opacity': spotlight, mask: 'url(#screenshot-spotlight)'}, svg);
+	}
+
+	let markers = 0;
+	for (let i = 0; i < annotations.length; i++) {
+		const a = annotations[i], s = a.style;
+		const p = locate(a);
+		if (!p) {
+			return {notFound: i};
+		}
+		if (a.type === 'arrow') {
+			const id = 'screenshot-arrow-' + i;
+			const head = node('marker', {id, viewBox: '0 0 10 10', refX: 8, refY: 5,
+				markerWidth: 4, markerHeight: 4, orient: 'auto-start-reverse'}, defs);
+			node('path', {d: 'M0,0 L10,5 L0,10 z', fill: s.color}, head);
+			node('line', {x1: p.x, y1: p.y, x2: (a.x2 || 0) + p.dx, y2: (a.y2 || 0) + p.dy, stroke: s.color,
+				'stroke-width': s.stroke_width, 'stroke-linecap': 'round', 'marker-end': 'url(#' + id + ')'}, svg);
+		} else if (a.type === 'callout') {
+			const div = document.createElement('div');
+			div.className = 'screenshot-callout';
+			div.textContent = a.text;
+			overlay(div, {
+				left: p.x + 'px',
+				top: p.y + 'px',
+				maxWidth: '320px',
+				padding: '0.4em 0.6em',
+				borderRadius: '4px',
+				background: s.color,
+				color: s.text_color,
+				font: s.font_size + 'px/1.3 sans-serif',
+				whiteSpace: 'pre-wrap',
+				boxShadow: '0 1px 4px rgba(0, 0, 0, 0.3)',
+			});
+		} else if (a.type === 'marker') {
+			markers++;
+			const size = s.font_size * 2;
+			const div = document.createElement('div');
+			div.className = 'screenshot-marker';
+			div.textContent = a.text || String(markers);
+			overlay(div, {
+				left: (p.x - size / 2) + 'px',
+				top: (p.y - size / 2) + 'px',
+				minWidth: size + 'px',
+				height: size + 'px',
+				boxSizing: 'border-box',
+				padding: '0 0.3em',
+				borderRadius: size / 2 + 'px',
+				display: 'flex',
+				alignItems: 'center',
+				justifyContent: 'center',
+				background: s.color,
+				color: s.text_color,
+				font: 'bold ' + s.font_size + 'px sans-serif',
+			});
+		}
+	}
+	return {};
+}`
+
+// annotationsArg аргумент drawAnnotationsJS: аннотации с итоговым стилем, непрозрачность затемнения
+// (0 - без затемнения) и holes - прямоугольники выделений в координатах страницы
+func annotationsArg(opts ScreenshotOptions, holes []interface{}) (map[string]interface{}, error) {
+	base := defaultAnnotationStyle.merge(opts.AnnotationStyle)
+	annotations := make([]Annotation, len(opts.Annotations))
+	for i, a := range opts.Annotations {
+		style := base.merge(a.Style)
+		a.Style = &style
+		annotations[i] = a
+	}
+	jsAnnotations, err := jsValue(annotations)
+	if err != nil {
+		return nil, err
+	}
+	spotlight := 0.0
+	if opts.Spotlight {
+		spotlight = opts.spotlightOpacity()
+	}
+	jsHoles, err := jsValue(holes)
+	if err != nil {
+		return nil, err
+	}
+	if jsHoles == nil {
+		jsHoles = []interface{}{}
+	}
+	return map[string]interface{}{
+		"annotations": jsAnnotations,
+		"spotlight":   spotlight,
+		"holes":       jsHoles,
+	}, nil
+}
+
+// drawAnnotations рисует аннотации и затемнение вне holes - прямоугольников выделений в координатах страницы
+func drawAnnotations(page playwright.Page, opts ScreenshotOptions, holes []interface{}) error {
+	arg, err := annotationsArg(opts, holes)
+	if err != nil {
+		return fmt.Errorf("failed to draw annotations: %w", err)
+	}
+	v, err := page.Evaluate(drawAnnotationsJS, arg)
+	if err != nil {
+		return fmt.Errorf("failed to draw annotations: %w", err)
+	}
+	result, _ := v.(map[string]interface{})
+	if i, ok := result["notFound"]; ok {
+		a := opts.Annotations[toInt(i)]
+		return &AnnotationError{Index: toInt(i), Type: a.Type, Selector: a.Selector, Message: "element not found"}
+	}
+	return nil
+}
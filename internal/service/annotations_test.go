@@ -0,0 +1,92 @@
+package service
+
+import "testing"
+
+func TestAnnotationsArg(t *testing.T) {
+	opts := ScreenshotOptions{
+		Annotations: []Annotation{
+			{Type: AnnotationArrow, X: 1, Y: 2, X2: 30, Y2: 40},
+			{Type: AnnotationCallout, X: 5, Y: 6, Text: "note", Space: SelectionElement, Selector: "#card",
+				Style: &AnnotationStyle{Color: "blue", FontSize: 20}},
+		},
+		AnnotationStyle: &AnnotationStyle{TextColor: "black"},
+		Selections:      []SelectionArea{{Width: 10, Height: 10}},
+		Spotlight:       true,
+	}
+	holes := []interface{}{map[string]interface{}{"x": 0.0, "y": 0.0, "width": 10.0, "height": 10.0}}
+	arg, err := annotationsArg(opts, holes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertJSValue(t, "arg", arg)
+
+	items, ok := arg["annotations"].([]interface{})
+	if !ok || len(items) != 2 {
+		t.Fatalf("annotations = %#v, want 2 items", arg["annotations"])
+	}
+	arrow, _ := items[0].(map[string]interface{})
+	if arrow["type"] != AnnotationArrow || arrow["x2"] != 30.0 || arrow["y2"] != 40.0 {
+		t.Errorf("annotation 0 = %#v", arrow)
+	}
+	style, _ := arrow["style"].(map[string]interface{})
+	want := map[string]interface{}{"color": "red", "text_color": "black", "font_size": 14.0, "stroke_width": 3.0}
+	for key, value := range want {
+		if style[key] != value {
+			t.Errorf("annotation 0 style.%s = %#v, want %#v", key, style[key], value)
+		}
+	}
+	callout, _ := items[1].(map[string]interface{})
+	if callout["text"] != "note" || callout["selector"] != "#card" || callout["space"] != SelectionElement {
+		t.Errorf("annotation 1 = %#v", callout)
+	}
+	if style, _ := callout["style"].(map[string]interface{}); style["color"] != "blue" || style["font_size"] != 20.0 ||
+		style["text_color"] != "black" {
+		t.Errorf("annotation 1 style = %#v", style)
+	}
+
+	if arg["spotlight"] != DefaultSpotlightOpacity {
+		t.Errorf("spotlight = %#v, want %v", arg["spotlight"], DefaultSpotlightOpacity)
+	}
+	if got, _ := arg["holes"].([]interface{}); len(got) != 1 {
+		t.Errorf("holes = %#v, want 1 item", arg["holes"])
+	}
+
+	// Без выделений и затемнения скрипт получает пустые массивы
+	arg, err = annotationsArg(ScreenshotOptions{Annotations: []Annotation{{Type: AnnotationMarker}}}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got, ok := arg["holes"].([]interface{}); !ok || len(got) != 0 {
+		t.Errorf("holes = %#v, want empty array", arg["holes"])
+	}
+	if arg["spotlight"] != 0.0 {
+		t.Errorf("spotlight = %#v, want 0", arg["spotlight"])
+	}
+}
+
+func TestValidateAnnotations(t *testing.T) {
+	tests := []struct {
+		name string
+		opts ScreenshotOptions
+		ok   bool
+	}{
+		{"arrow", ScreenshotOptions{Annotations: []Annotation{{Type: AnnotationArrow, X2: 10}}}, true},
+		{"marker in element", ScreenshotOptions{Annotations: []Annotation{{Type: AnnotationMarker, Space: SelectionElement, Selector: "#a"}}}, true},
+		{"spotlight", ScreenshotOptions{Selections: []SelectionArea{{Width: 1, Height: 1}}, Spotlight: true, SpotlightOpacity: 0.3}, true},
+		{"empty arrow", ScreenshotOptions{Annotations: []Annotation{{Type: AnnotationArrow}}}, false},
+		{"callout without text", ScreenshotOptions{Annotations: []Annotation{{Type: AnnotationCallout}}}, false},
+		{"unknown type", ScreenshotOptions{Annotations: []Annotation{{Type: "circle"}}}, false},
+		{"element without selector", ScreenshotOptions{Annotations: []Annotation{{Type: AnnotationMarker, Space: SelectionElement}}}, false},
+		{"negative font size", ScreenshotOptions{Annotations: []Annotation{{Type: AnnotationMarker, Style: &AnnotationStyle{FontSize: -1}}}}, false},
+		{"scroll container", ScreenshotOptions{Annotations: []Annotation{{Type: AnnotationMarker}}, ScrollContainer: "#list"}, false},
+		{"spotlight without selections", ScreenshotOptions{Spotlight: true}, false},
+		{"spotlight opacity", ScreenshotOptions{Selections: []SelectionArea{{Width: 1, Height: 1}}, SpotlightOpacity: 2}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateAnnotations(tt.opts); (err == nil) != tt.ok {
+				t.Errorf("ValidateAnnotations() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
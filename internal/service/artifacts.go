@@ -0,0 +1,80 @@
+package service
+
+import (
+	"fmt"
+	"github.com/playwright-community/playwright-go"
+	"os"
+	"path/filepath"
+)
+
+// Artifacts отладочные артефакты рендера
+type Artifacts struct {
+	HAR   []byte // Сетевая активность страницы в формате HAR
+	Trace []byte // Архив трассы playwright, открывается в `playwright show-trace`
+}
+
+// artifactRecorder записывает HAR и трассу playwright для отдельного контекста браузера
+type artifactRecorder struct {
+	dir     string
+	context playwright.BrowserContext
+	closed  bool
+}
+
+// newArtifactRecorder создает контекст браузера с настройками opts и записью HAR, запускает трассировку
+func newArtifactRecorder(browser playwright.Browser, opts playwright.BrowserNewContextOptions) (*artifactRecorder, error) {
+	dir, err := os.MkdirTemp("", "screenshot_artifacts_")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create artifacts dir: %w", err)
+	}
+	r := &artifactRecorder{dir: dir}
+
+	opts.RecordHarPath = playwright.String(filepath.Join(dir, "network.har"))
+	opts.RecordHarContent = playwright.HarContentPolicyEmbed
+	r.context, err = browser.NewContext(opts)
+	if err != nil {
+		r.cleanup()
+		return nil, fmt.Errorf("failed to create recording context: %w", err)
+	}
+
+	if err := r.context.Tracing().Start(playwright.TracingStartOptions{
+		Screenshots: playwright.Bool(true),
+		Snapshots:   playwright.Bool(true),
+	}); err != nil {
+		r.cleanup()
+		return nil, fmt.Errorf("failed to start trace: %w", err)
+	}
+
+	return r, nil
+}
+
+// finish останавливает трассировку, закрывает контекст (HAR записывается при закрытии) и читает артефакты
+func (r *artifactRecorder) finish() (*Artifacts, error) {
+	tracePath := filepath.Join(r.dir, "trace.zip")
+	if err := r.context.Tracing().Stop(tracePath); err != nil {
+		return nil, fmt.Errorf("failed to stop trace: %w", err)
+	}
+	r.closed = true
+	if err := r.context.Close(); err != nil {
+		return nil, fmt.Errorf("failed to close recording context: %w", err)
+	}
+
+	har, err := os.ReadFile(filepath.Join(r.dir, "network.har"))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read har: %w", err)
+	}
+	trace, err := os.ReadFile(tracePath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read trace: %w", err)
+	}
+
+	return &Artifacts{HAR: har, Trace: trace}, nil
+}
+
+// cleanup закрывает контекст, если он еще открыт, и удаляет временные файлы
+func (r *artifactRecorder) cleanup() error {
+	if r.context != nil && !r.closed {
+		r.closed = true
+		_ = r.context.Close()
+	}
+	return os.RemoveAll(r.dir)
+}
@@ -0,0 +1,178 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// DefaultBundleEntry точка входа набора файлов по умолчанию
+const DefaultBundleEntry = "index.html"
+
+// BundleLimits ограничения набора файлов страницы
+type BundleLimits struct {
+	MaxSize  int64 // Максимальный суммарный размер распакованных файлов (байт), 0 - без ограничения
+	MaxFiles int   // Максимальное число файлов, 0 - без ограничения
+}
+
+// BundleFile файл набора
+type BundleFile struct {
+	Name string // Относительный путь внутри набора
+	Data io.Reader
+}
+
+// Bundle html-страница с картинками, стилями и шрифтами во временной директории.
+// Относительные ссылки страницы разрешаются внутри директории
+type Bundle struct {
+	Dir   string
+	Entry string // Путь html-файла точки входа относительно Dir
+
+	limits BundleLimits
+	size   int64
+	files  int
+}
+
+// NewBundle создает пустой набор во временной директории
+func NewBundle(limits BundleLimits) (*Bundle, error) {
+	dir, err := os.MkdirTemp("", "screenshot_bundle_")
+	if err != nil {
+		return nil, fmt.Errorf("failed to create bundle dir: %w", err)
+	}
+	return &Bundle{Dir: dir, limits: limits}, nil
+}
+
+// ExtractZip распаковывает ZIP-архив в новый набор.
+// Если entry пустой, точкой входа становится index.html или единственный html-файл архива
+func ExtractZip(data []byte, entry string, limits BundleLimits) (*Bundle, error) {
+	zr, err := zip.NewReader(bytes.NewReader(data), int64(len(data)))
+	if err != nil {
+		return nil, fmt.Errorf("invalid bundle archive: %w", err)
+	}
+
+	b, err := NewBundle(limits)
+	if err != nil {
+		return nil, err
+	}
+
+	var htmlFiles []string
+	for _, f := range zr.File {
+		if f.FileInfo().IsDir() {
+			continue
+		}
+		if !f.Mode().IsRegular() {
+			b.Close()
+			return nil, fmt.Errorf("bundle entry %q is not a regular file", f.Name)
+		}
+		// Заявленный размер проверяем до распаковки, фактический - при записи
+		if limits.MaxSize > 0 && b.size+int64(f.UncompressedSize64) > limits.MaxSize {
+			b.Close()
+			return nil, &LimitError{Limit: "max_bundle_size", Value: b.size + int64(f.UncompressedSize64), Max: limits.MaxSize}
+		}
+
+		rc, err := f.Open()
+		if err != nil {
+			b.Close()
+			return nil, fmt.Errorf("invalid bundle entry %q: %w", f.Name, err)
+		}
+		err = b.Add(BundleFile{Name: f.Name, Data: rc})
+		rc.Close()
+		if err != nil {
+			b.Close()
+			return nil, err
+		}
+
+		if ext := strings.ToLower(path.Ext(f.Name)); ext == ".html" || ext == ".htm" {
+			htmlFiles = append(htmlFiles, f.Name)
+		}
+	}
+
+	if entry == "" {
+		entry = DefaultBundleEntry
+		if _, err := os.Stat(filepath.Join(b.Dir, entry)); err != nil && len(htmlFiles) == 1 {
+			entry = htmlFiles[0]
+		}
+	}
+	if err := b.SetEntry(entry); err != nil {
+		b.Close()
+		return nil, err
+	}
+
+	return b, nil
+}
+
+// Add записывает файл в набор, проверяя путь и ограничения
+func (b *Bundle) Add(f BundleFile) error {
+	name, err := bundlePath(f.Name)
+	if err != nil {
+		return err
+	}
+
+	b.files++
+	if b.limits.MaxFiles > 0 && b.files > b.limits.MaxFiles {
+		return &LimitError{Limit: "max_bundle_files", Value: b.files, Max: b.limits.MaxFiles}
+	}
+
+	dst := filepath.Join(b.Dir, name)
+	if err := os.MkdirAll(filepath.Dir(dst), 0755); err != nil {
+		return fmt.Errorf("failed to write bundle file %q: %w", f.Name, err)
+	}
+	out, err := os.OpenFile(dst, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to write bundle file %q: %w", f.Name, err)
+	}
+	defer out.Close()
+
+	// Читаем на байт больше остатка, чтобы заметить превышение
+	src := f.Data
+	if b.limits.MaxSize > 0 {
+		src = io.LimitReader(f.Data, b.limits.MaxSize-b.size+1)
+	}
+	n, err := io.Copy(out, src)
+	b.size += n
+	if err != nil {
+		return fmt.Errorf("failed to write bundle file %q: %w", f.Name, err)
+	}
+	if b.limits.MaxSize > 0 && b.size > b.limits.MaxSize {
+		return &LimitError{Limit: "max_bundle_size", Value: b.size, Max: b.limits.MaxSize}
+	}
+
+	return nil
+}
+
+// SetEntry задает html-файл точки входа
+func (b *Bundle) SetEntry(entry string) error {
+	name, err := bundlePath(entry)
+	if err != nil {
+		return err
+	}
+	info, err := os.Stat(filepath.Join(b.Dir, name))
+	if err != nil || !info.Mode().IsRegular() {
+		return fmt.Errorf("bundle entry %q not found", entry)
+	}
+	b.Entry = name
+	return nil
+}
+
+// URL адрес точки входа для браузера
+func (b *Bundle) URL() string {
+	return "file://" + filepath.ToSlash(filepath.Join(b.Dir, b.Entry))
+}
+
+// Close удаляет временную директорию набора
+func (b *Bundle) Close() error {
+	return os.RemoveAll(b.Dir)
+}
+
+// bundlePath проверяет, что путь файла остается внутри набора
+func bundlePath(name string) (string, error) {
+	local := filepath.FromSlash(strings.ReplaceAll(name, "\\", "/"))
+	if !filepath.IsLocal(local) {
+		return "", fmt.Errorf("invalid bundle path %q", name)
+	}
+	return filepath.Clean(local), nil
+}
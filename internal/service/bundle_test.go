@@ -0,0 +1,118 @@
+package service
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func zipBundle(t *testing.T, files map[string]string) []byte {
+	t.Helper()
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range files {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestBundlePath(t *testing.T) {
+	tests := []struct {
+		name string
+		want string
+		ok   bool
+	}{
+		{"index.html", "index.html", true},
+		{"css/style.css", filepath.Join("css", "style.css"), true},
+		{"img\\logo.png", filepath.Join("img", "logo.png"), true},
+		{"a/../b.css", "b.css", true},
+		{"../secret", "", false},
+		{"a/../../secret", "", false},
+		{"..\\secret", "", false},
+		{"/etc/passwd", "", false},
+		{"", "", false},
+	}
+	for _, tt := range tests {
+		got, err := bundlePath(tt.name)
+		if (err == nil) != tt.ok || got != tt.want {
+			t.Errorf("bundlePath(%q) = %q, %v, want %q, ok %v", tt.name, got, err, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestExtractZip(t *testing.T) {
+	data := zipBundle(t, map[string]string{
+		"index.html":    "<h1>Test</h1>",
+		"css/style.css": "h1 {}",
+	})
+	b, err := ExtractZip(data, "", BundleLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer b.Close()
+	if b.Entry != "index.html" || !strings.HasSuffix(b.URL(), "/index.html") {
+		t.Errorf("entry = %q, url = %q", b.Entry, b.URL())
+	}
+	if content, err := os.ReadFile(filepath.Join(b.Dir, "css", "style.css")); err != nil || string(content) != "h1 {}" {
+		t.Errorf("css/style.css = %q, %v", content, err)
+	}
+
+	// Единственный html-файл становится точкой входа
+	b, err = ExtractZip(zipBundle(t, map[string]string{"page.html": "<p></p>"}), "", BundleLimits{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	b.Close()
+	if b.Entry != "page.html" {
+		t.Errorf("entry = %q, want page.html", b.Entry)
+	}
+	if _, err := os.Stat(b.Dir); !os.IsNotExist(err) {
+		t.Errorf("bundle dir is not removed: %v", err)
+	}
+}
+
+func TestExtractZipRejects(t *testing.T) {
+	var limitErr *LimitError
+	tests := []struct {
+		name   string
+		files  map[string]string
+		entry  string
+		limits BundleLimits
+		limit  string // Ожидаемое ограничение, пусто - обычная ошибка
+	}{
+		{name: "path traversal", files: map[string]string{"../evil.html": "x"}},
+		{name: "absolute path", files: map[string]string{"/tmp/evil.html": "x"}},
+		{name: "entry outside", files: map[string]string{"index.html": "x"}, entry: "../index.html"},
+		{name: "missing entry", files: map[string]string{"a.html": "x", "b.html": "y"}},
+		{name: "size", files: map[string]string{"index.html": strings.Repeat("x", 100)}, limits: BundleLimits{MaxSize: 10}, limit: "max_bundle_size"},
+		{name: "files", files: map[string]string{"index.html": "x", "a.css": "y"}, limits: BundleLimits{MaxFiles: 1}, limit: "max_bundle_files"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			b, err := ExtractZip(zipBundle(t, tt.files), tt.entry, tt.limits)
+			if err == nil {
+				b.Close()
+				t.Fatal("bundle is accepted")
+			}
+			if tt.limit != "" && (!errors.As(err, &limitErr) || limitErr.Limit != tt.limit) {
+				t.Errorf("error = %v, want %s limit error", err, tt.limit)
+			}
+		})
+	}
+	if _, err := ExtractZip([]byte("not a zip"), "", BundleLimits{}); err == nil {
+		t.Error("broken archive is accepted")
+	}
+}
@@ -0,0 +1,174 @@
+package service
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Secret значение, которое нельзя выводить в журналы.
+// При форматировании заменяется на [REDACTED], в JSON (ключи кеша, ETag) - на sha256
+type Secret string
+
+func (s Secret) String() string {
+	if s == "" {
+		return ""
+	}
+	return "[REDACTED]"
+}
+
+// GoString скрывает значение и в %#v
+func (s Secret) GoString() string {
+	return s.String()
+}
+
+// MarshalJSON хеш значения: разные секреты дают разные ключи кеша, но исходное значение не раскрывается
+func (s Secret) MarshalJSON() ([]byte, error) {
+	if s == "" {
+		return []byte(`""`), nil
+	}
+	sum := sha256.Sum256([]byte(s))
+	return json.Marshal("sha256:" + hex.EncodeToString(sum[:]))
+}
+
+// Cookie кука, которая устанавливается в контекст браузера до загрузки страницы.
+// Без url и domain кука привязывается к адресу страницы
+type Cookie struct {
+	Name     string  `json:"name"`
+	Value    Secret  `json:"value"`
+	URL      string  `json:"url,omitempty"`
+	Domain   string  `json:"domain,omitempty"`
+	Path     string  `json:"path,omitempty"`
+	Expires  float64 `json:"expires,omitempty"` // Unix-время в секундах
+	HTTPOnly bool    `json:"http_only,omitempty"`
+	Secure   bool    `json:"secure,omitempty"`
+	SameSite string  `json:"same_site,omitempty"` // Strict, Lax или None
+}
+
+// HTTPCredentials логин и пароль HTTP basic-аутентификации
+type HTTPCredentials struct {
+	Username string `json:"username"`
+	Password Secret `json:"password"`
+}
+
+// Credentials заголовки, куки и другие данные авторизации страницы
+type Credentials struct {
+	ExtraHTTPHeaders map[string]Secret `json:"extra_http_headers,omitempty"` // Заголовки всех запросов страницы
+	Cookies          []Cookie          `json:"cookies,omitempty"`
+	HTTPCredentials  *HTTPCredentials  `json:"http_credentials,omitempty"`
+	UserAgent        string            `json:"user_agent,omitempty"`
+	StorageState     Secret            `json:"storage_state,omitempty"` // Состояние playwright (cookies и localStorage) в JSON
+}
+
+// Validate проверяет куки и состояние хранилища
+func (c Credentials) Validate() error {
+	for name := range c.ExtraHTTPHeaders {
+		if name == "" || strings.ContainsAny(name, " :\r\n") {
+			return fmt.Errorf("extra_http_headers: invalid header name %q", name)
+		}
+	}
+	for i, cookie := range c.Cookies {
+		if cookie.Name == "" {
+			return fmt.Errorf("cookies[%d]: name is required", i)
+		}
+		switch cookie.SameSite {
+		case "", "Strict", "Lax", "None":
+		default:
+			return fmt.Errorf("cookies[%d]: same_site must be Strict, Lax or None", i)
+		}
+	}
+	if c.HTTPCredentials != nil && c.HTTPCredentials.Username == "" {
+		return fmt.Errorf("http_credentials: username is required")
+	}
+	if c.StorageState != "" {
+		if _, err := c.storageState(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// contextOptions добавляет заголовки, учетные данные, user agent и состояние хранилища к настройкам контекста
+func (c Credentials) contextOptions(o *playwright.BrowserNewContextOptions) error {
+	if len(c.ExtraHTTPHeaders) > 0 && o.ExtraHttpHeaders == nil {
+		o.ExtraHttpHeaders = make(map[string]string, len(c.ExtraHTTPHeaders))
+	}
+	for name, value := range c.ExtraHTTPHeaders {
+		o.ExtraHttpHeaders[name] = string(value)
+	}
+	if c.HTTPCredentials != nil {
+		o.HttpCredentials = &playwright.HttpCredentials{
+			Username: c.HTTPCredentials.Username,
+			Password: string(c.HTTPCredentials.Password),
+		}
+	}
+	if c.UserAgent != "" {
+		o.UserAgent = playwright.String(c.UserAgent)
+	}
+	if c.StorageState != "" {
+		state, err := c.storageState()
+		if err != nil {
+			return err
+		}
+		o.StorageState = state
+	}
+	return nil
+}
+
+// addCookies устанавливает куки в контекст; куки без url и domain получают адрес страницы pageURL
+func (c Credentials) addCookies(browserContext playwright.BrowserContext, pageURL string) error {
+	if len(c.Cookies) == 0 {
+		return nil
+	}
+	cookies := make([]playwright.OptionalCookie, 0, len(c.Cookies))
+	for _, cookie := range c.Cookies {
+		pc := playwright.OptionalCookie{Name: cookie.Name, Value: string(cookie.Value)}
+		switch {
+		case cookie.URL != "":
+			pc.URL = playwright.String(cookie.URL)
+		case cookie.Domain != "":
+			pc.Domain = playwright.String(cookie.Domain)
+			path := cookie.Path
+			if path == "" {
+				path = "/"
+			}
+			pc.Path = playwright.String(path)
+		case strings.HasPrefix(pageURL, "http://") || strings.HasPrefix(pageURL, "https://"):
+			pc.URL = playwright.String(pageURL)
+		default:
+			return fmt.Errorf("cookie %q requires url or domain for a page without http address", cookie.Name)
+		}
+		if cookie.Expires > 0 {
+			pc.Expires = playwright.Float(cookie.Expires)
+		}
+		if cookie.HTTPOnly {
+			pc.HttpOnly = playwright.Bool(true)
+		}
+		if cookie.Secure {
+			pc.Secure = playwright.Bool(true)
+		}
+		if cookie.SameSite != "" {
+			pc.SameSite = (*playwright.SameSiteAttribute)(&cookie.SameSite)
+		}
+		cookies = append(cookies, pc)
+	}
+	if err := browserContext.AddCookies(cookies); err != nil {
+		// Текст ошибки playwright может содержать значения кук
+		return fmt.Errorf("failed to set cookies")
+	}
+	return nil
+}
+
+// storageState разбирает состояние в формате BrowserContext.StorageState playwright
+func (c Credentials) storageState() (*playwright.OptionalStorageState, error) {
+	var state playwright.OptionalStorageState
+	if err := json.Unmarshal([]byte(c.StorageState), &state); err != nil {
+		// Ошибка разбора не должна раскрывать содержимое
+		return nil, fmt.Errorf("storage_state must be a playwright storage state JSON")
+	}
+	return &state, nil
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"testing"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+func TestSecret(t *testing.T) {
+	s := Secret("token-value")
+	for _, format := range []string{"%s", "%v", "%+v", "%#v"} {
+		if got := fmt.Sprintf(format, s); strings.Contains(got, "token-value") {
+			t.Errorf("%s reveals the secret: %s", format, got)
+		}
+	}
+	creds := Credentials{
+		ExtraHTTPHeaders: map[string]Secret{"Authorization": "Bearer token-value"},
+		Cookies:          []Cookie{{Name: "session", Value: "token-value"}},
+		HTTPCredentials:  &HTTPCredentials{Username: "user", Password: "token-value"},
+	}
+	if got := fmt.Sprintf("%+v %#v", creds, creds); strings.Contains(got, "token-value") {
+		t.Errorf("credentials reveal the secret: %s", got)
+	}
+
+	data, err := json.Marshal(creds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(string(data), "token-value") || !strings.Contains(string(data), `"sha256:`) {
+		t.Errorf("json = %s, want hashed secrets", data)
+	}
+	// Разные секреты дают разные хеши, пустой секрет остается пустым
+	a, _ := json.Marshal(Secret("a"))
+	b, _ := json.Marshal(Secret("b"))
+	if string(a) == string(b) {
+		t.Error("different secrets have the same hash")
+	}
+	if empty, _ := json.Marshal(Secret("")); string(empty) != `""` {
+		t.Errorf("empty secret = %s", empty)
+	}
+	if Secret("").String() != "" {
+		t.Error("empty secret is formatted as redacted")
+	}
+}
+
+func TestCredentialsValidate(t *testing.T) {
+	tests := []struct {
+		name  string
+		creds Credentials
+		ok    bool
+	}{
+		{"empty", Credentials{}, true},
+		{"full", Credentials{
+			ExtraHTTPHeaders: map[string]Secret{"X-Token": "1"},
+			Cookies:          []Cookie{{Name: "a", Value: "1", SameSite: "Lax"}},
+			HTTPCredentials:  &HTTPCredentials{Username: "user"},
+			StorageState:     `{"cookies":[],"origins":[]}`,
+		}, true},
+		{"empty header name", Credentials{ExtraHTTPHeaders: map[string]Secret{"": "1"}}, false},
+		{"header name with colon", Credentials{ExtraHTTPHeaders: map[string]Secret{"X-A:": "1"}}, false},
+		{"header name with newline", Credentials{ExtraHTTPHeaders: map[string]Secret{"X-A\r\nX-B": "1"}}, false},
+		{"cookie without name", Credentials{Cookies: []Cookie{{Value: "1"}}}, false},
+		{"cookie same site", Credentials{Cookies: []Cookie{{Name: "a", SameSite: "lax"}}}, false},
+		{"http credentials without username", Credentials{HTTPCredentials: &HTTPCredentials{Password: "1"}}, false},
+		{"broken storage state", Credentials{StorageState: "{token-value"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := tt.creds.Validate()
+			if (err == nil) != tt.ok {
+				t.Errorf("Validate() = %v, want ok %v", err, tt.ok)
+			}
+			if err != nil && strings.Contains(err.Error(), "token-value") {
+				t.Errorf("error reveals the secret: %v", err)
+			}
+		})
+	}
+}
+
+func TestCredentialsContextOptions(t *testing.T) {
+	creds := Credentials{
+		ExtraHTTPHeaders: map[string]Secret{"X-Token": "1"},
+		HTTPCredentials:  &HTTPCredentials{Username: "user", Password: "pass"},
+		UserAgent:        "bot",
+	}
+	o := playwright.BrowserNewContextOptions{ExtraHttpHeaders: map[string]string{"X-Other": "2"}}
+	if err := creds.contextOptions(&o); err != nil {
+		t.Fatal(err)
+	}
+	if o.ExtraHttpHeaders["X-Token"] != "1" || o.ExtraHttpHeaders["X-Other"] != "2" {
+		t.Errorf("headers = %v", o.ExtraHttpHeaders)
+	}
+	if o.HttpCredentials == nil || o.HttpCredentials.Password != "pass" {
+		t.Errorf("http credentials = %+v", o.HttpCredentials)
+	}
+	if o.UserAgent == nil || *o.UserAgent != "bot" {
+		t.Errorf("user agent = %v", o.UserAgent)
+	}
+}
@@ -0,0 +1,122 @@
+package service
+
+import (
+	"fmt"
+	"github.com/playwright-community/playwright-go"
+	"strings"
+	"sync"
+)
+
+const (
+	maxDiagnosticEntries = 100  // Максимальное число записей каждого вида
+	maxDiagnosticText    = 2000 // Максимальная длина текста записи
+)
+
+// Diagnostics что происходило на странице во время рендера
+type Diagnostics struct {
+	Console        []ConsoleMessage `json:"console"`
+	PageErrors     []string         `json:"page_errors"`     // Необработанные исключения JavaScript
+	FailedRequests []FailedRequest  `json:"failed_requests"` // Неудачные, заблокированные запросы и ответы 4xx/5xx
+}
+
+// ConsoleMessage сообщение console.* страницы
+type ConsoleMessage struct {
+	Type     string `json:"type"` // log, info, warning, error, debug, ...
+	Text     string `json:"text"`
+	Location string `json:"location,omitempty"` // url:строка:столбец
+}
+
+// FailedRequest запрос страницы, завершившийся ошибкой
+type FailedRequest struct {
+	URL          string `json:"url"`
+	Method       string `json:"method"`
+	ResourceType string `json:"resource_type"`
+	Status       int    `json:"status,omitempty"` // HTTP-статус, если ответ получен
+	Error        string `json:"error,omitempty"`  // Ошибка сети или блокировки
+}
+
+// PageError рендер прерван из-за исключений на странице (fail_on_page_error)
+type PageError struct {
+	Diagnostics Diagnostics
+}
+
+func (e *PageError) Error() string {
+	return fmt.Sprintf("page threw %d uncaught error(s): %s", len(e.Diagnostics.PageErrors), e.Diagnostics.PageErrors[0])
+}
+
+// diagnosticsCollector собирает события страницы, обработчики вызываются из горутин playwright
+type diagnosticsCollector struct {
+	mu sync.Mutex
+	d  Diagnostics
+}
+
+// attach подписывается на события страницы
+func (c *diagnosticsCollector) attach(page playwright.Page) {
+	page.OnConsole(func(msg playwright.ConsoleMessage) {
+		m := ConsoleMessage{Type: msg.Type(), Text: truncate(msg.Text())}
+		if loc := msg.Location(); loc != nil && loc.URL != "" {
+			m.Location = fmt.Sprintf("%s:%d:%d", loc.URL, loc.LineNumber+1, loc.ColumnNumber+1)
+		}
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if len(c.d.Console) < maxDiagnosticEntries {
+			c.d.Console = append(c.d.Console, m)
+		}
+	})
+	page.OnPageError(func(err error) {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if len(c.d.PageErrors) < maxDiagnosticEntries {
+			c.d.PageErrors = append(c.d.PageErrors, truncate(err.Error()))
+		}
+	})
+	page.OnRequestFailed(func(req playwright.Request) {
+		r := failedRequest(req)
+		if err := req.Failure(); err != nil {
+			r.Error = truncate(err.Error())
+		}
+		c.add(r)
+	})
+	page.OnResponse(func(resp playwright.Response) {
+		if resp.Status() < 400 {
+			return
+		}
+		r := failedRequest(resp.Request())
+		r.Status = resp.Status()
+		c.add(r)
+	})
+}
+
+func (c *diagnosticsCollector) add(r FailedRequest) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if len(c.d.FailedRequests) < maxDiagnosticEntries {
+		c.d.FailedRequests = append(c.d.FailedRequests, r)
+	}
+}
+
+// snapshot копия собранных событий
+func (c *diagnosticsCollector) snapshot() Diagnostics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return Diagnostics{
+		Console:        append([]ConsoleMessage{}, c.d.Console...),
+		PageErrors:     append([]string{}, c.d.PageErrors...),
+		FailedRequests: append([]FailedRequest{}, c.d.FailedRequests...),
+	}
+}
+
+func failedRequest(req playwright.Request) FailedRequest {
+	return FailedRequest{
+		URL:          truncate(req.URL()),
+		Method:       req.Method(),
+		ResourceType: req.ResourceType(),
+	}
+}
+
+func truncate(s string) string {
+	if len(s) > maxDiagnosticText {
+		return strings.ToValidUTF8(s[:maxDiagnosticText], "") + "..."
+	}
+	return s
+}
@@ -0,0 +1,339 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	"image/png"
+	"math"
+)
+
+// regionCellSize размер ячейки сетки, по которой отличия объединяются в области
+const regionCellSize = 8
+
+var (
+	diffColor = color.RGBA{R: 255, A: 255}
+	aaColor   = color.RGBA{R: 255, G: 255, A: 255}
+)
+
+// checkDiffSize проверяет размеры изображения по заголовку
+func checkDiffSize(data []byte, name string, opts DiffOptions) error {
+	config, _, err := image.DecodeConfig(bytes.NewReader(data))
+	if err != nil {
+		return fmt.Errorf("failed to decode %s: %w", name, err)
+	}
+	if opts.MaxWidth > 0 && config.Width > opts.MaxWidth {
+		return &LimitError{Limit: "max_viewport_width", Value: config.Width, Max: opts.MaxWidth}
+	}
+	if opts.MaxHeight > 0 && config.Height > opts.MaxHeight {
+		return &LimitError{Limit: "max_full_page_height", Value: config.Height, Max: opts.MaxHeight}
+	}
+	return nil
+}
+
+// Validate проверяет параметры сравнения, не зависящие от изображений
+func (o DiffOptions) Validate() error {
+	if o.Threshold < 0 || o.Threshold > 1 {
+		return fmt.Errorf("threshold must be between 0 and 1")
+	}
+	if o.AATolerance < 0 || o.AATolerance > 8 {
+		return fmt.Errorf("aa_tolerance must be between 0 and 8")
+	}
+	return nil
+}
+
+// ImageDiff попиксельное сравнение изображений (алгоритм в духе pixelmatch)
+type ImageDiff struct{}
+
+func NewImageDiff() *ImageDiff {
+	return &ImageDiff{}
+}
+
+// Compare сравнивает эталонное изображение с текущим
+func (d *ImageDiff) Compare(baseline, actual []byte, opts DiffOptions) (*DiffResult, error) {
+	if err := opts.Validate(); err != nil {
+		return nil, err
+	}
+	// Размеры проверяются по заголовкам до декодирования: маленький файл может объявить огромное изображение
+	if err := checkDiffSize(baseline, "baseline image", opts); err != nil {
+		return nil, err
+	}
+	if err := checkDiffSize(actual, "image", opts); err != nil {
+		return nil, err
+	}
+	baseImg, _, err := image.Decode(bytes.NewReader(baseline))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode baseline image: %w", err)
+	}
+	actualImg, _, err := image.Decode(bytes.NewReader(actual))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode image: %w", err)
+	}
+
+	a := toRGBA(baseImg)
+	b := toRGBA(actualImg)
+
+	// Изображения разного размера сравниваются по объединённой области,
+	// пиксели за пределами одного из них считаются отличающимися
+	width := max(a.Bounds().Dx(), b.Bounds().Dx())
+	height := max(a.Bounds().Dy(), b.Bounds().Dy())
+
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+	mask := make([]bool, width*height)
+	maxDelta := 35215 * opts.Threshold * opts.Threshold
+	mismatched := 0
+
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			inA := x < a.Bounds().Dx() && y < a.Bounds().Dy()
+			inB := x < b.Bounds().Dx() && y < b.Bounds().Dy()
+			if !inA || !inB {
+				out.SetRGBA(x, y, diffColor)
+				mask[y*width+x] = true
+				mismatched++
+				continue
+			}
+
+			delta := colorDelta(a.RGBAAt(x, y), b.RGBAAt(x, y), false)
+			if math.Abs(delta) <= maxDelta {
+				out.SetRGBA(x, y, fadedGray(b.RGBAAt(x, y)))
+				continue
+			}
+
+			if opts.IgnoreAntialiasing &&
+				(isAntialiased(a, b, x, y, opts.AATolerance) || isAntialiased(b, a, x, y, opts.AATolerance)) {
+				out.SetRGBA(x, y, aaColor)
+				continue
+			}
+
+			out.SetRGBA(x, y, diffColor)
+			mask[y*width+x] = true
+			mismatched++
+		}
+	}
+
+	regions := findRegions(mask, width, height)
+
+	style := opts.SelectionStyle
+	if style == nil {
+		style = &SelectionStyle{
+			BorderColor: "#FF0000",
+			BorderWidth: 2,
+			BorderStyle: "dashed",
+			Opacity:     1.0,
+		}
+	}
+	for _, r := range regions {
+		rect := image.Rect(r.X, r.Y, r.X+r.Width, r.Y+r.Height).Inset(-style.BorderWidth)
+		if err := drawSelection(out, rect, style); err != nil {
+			return nil, fmt.Errorf("failed to draw diff region: %w", err)
+		}
+	}
+
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, out); err != nil {
+		return nil, fmt.Errorf("failed to encode diff image: %w", err)
+	}
+
+	total := width * height
+	percentage := 0.0
+	if total > 0 {
+		percentage = float64(mismatched) * 100 / float64(total)
+	}
+
+	return &DiffResult{
+		Width:              width,
+		Height:             height,
+		TotalPixels:        total,
+		MismatchedPixels:   mismatched,
+		MismatchPercentage: percentage,
+		Regions:            regions,
+		Image:              buf.Bytes(),
+	}, nil
+}
+
+// toRGBA приводит изображение к *image.RGBA с началом координат в (0, 0)
+func toRGBA(img image.Image) *image.RGBA {
+	b := img.Bounds()
+	rgba := image.NewRGBA(image.Rect(0, 0, b.Dx(), b.Dy()))
+	draw.Draw(rgba, rgba.Bounds(), img, b.Min, draw.Src)
+	return rgba
+}
+
+// blend накладывает цвет на белый фон с учётом прозрачности
+func blend(c uint8, alpha float64) float64 {
+	return 255 + (float64(c)-255)*alpha
+}
+
+// colorDelta квадрат разницы цветов в пространстве YIQ (или только яркость)
+func colorDelta(c1, c2 color.RGBA, yOnly bool) float64 {
+	if c1 == c2 {
+		return 0
+	}
+	a1, a2 := float64(c1.A)/255, float64(c2.A)/255
+	r1, g1, b1 := blend(c1.R, a1), blend(c1.G, a1), blend(c1.B, a1)
+	r2, g2, b2 := blend(c2.R, a2), blend(c2.G, a2), blend(c2.B, a2)
+
+	y := rgb2y(r1, g1, b1) - rgb2y(r2, g2, b2)
+	if yOnly {
+		return y
+	}
+	i := rgb2i(r1, g1, b1) - rgb2i(r2, g2, b2)
+	q := rgb2q(r1, g1, b1) - rgb2q(r2, g2, b2)
+
+	delta := 0.5053*y*y + 0.299*i*i + 0.1957*q*q
+	// Знак показывает, светлее или темнее стал пиксель
+	if rgb2y(r1, g1, b1) > rgb2y(r2, g2, b2) {
+		return -delta
+	}
+	return delta
+}
+
+func rgb2y(r, g, b float64) float64 { return r*0.29889531 + g*0.58662247 + b*0.11448223 }
+func rgb2i(r, g, b float64) float64 { return r*0.59597799 - g*0.27417610 - b*0.32180189 }
+func rgb2q(r, g, b float64) float64 { return r*0.21147017 - g*0.52261711 + b*0.31114694 }
+
+// fadedGray осветлённая серая версия пикселя для фона diff-изображения
+func fadedGray(c color.RGBA) color.RGBA {
+	y := rgb2y(blend(c.R, float64(c.A)/255), blend(c.G, float64(c.A)/255), blend(c.B, float64(c.A)/255))
+	v := uint8(blend(uint8(clamp(y, 0, 255)), 0.1))
+	return color.RGBA{R: v, G: v, B: v, A: 255}
+}
+
+// isAntialiased проверяет, является ли пиксель частью сглаживания контура
+func isAntialiased(img, other *image.RGBA, x, y, tolerance int) bool {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	x0, y0 := max(x-1, 0), max(y-1, 0)
+	x2, y2 := min(x+1, w-1), min(y+1, h-1)
+
+	zeroes := 0
+	if x == x0 || x == x2 || y == y0 || y == y2 {
+		zeroes = 1
+	}
+	minDelta, maxDelta := 0.0, 0.0
+	minX, minY, maxX, maxY := 0, 0, 0, 0
+
+	center := img.RGBAAt(x, y)
+	for ny := y0; ny <= y2; ny++ {
+		for nx := x0; nx <= x2; nx++ {
+			if nx == x && ny == y {
+				continue
+			}
+			delta := colorDelta(center, img.RGBAAt(nx, ny), true)
+			if delta == 0 {
+				zeroes++
+				if zeroes > tolerance {
+					return false
+				}
+			} else if delta < minDelta {
+				minDelta, minX, minY = delta, nx, ny
+			} else if delta > maxDelta {
+				maxDelta, maxX, maxY = delta, nx, ny
+			}
+		}
+	}
+
+	// Нет одновременно более тёмных и более светлых соседей - не сглаживание
+	if minDelta == 0 || maxDelta == 0 {
+		return false
+	}
+
+	return (hasManySiblings(img, minX, minY) && hasManySiblings(other, minX, minY)) ||
+		(hasManySiblings(img, maxX, maxY) && hasManySiblings(other, maxX, maxY))
+}
+
+// hasManySiblings проверяет, есть ли у пикселя хотя бы 3 соседа того же цвета
+func hasManySiblings(img *image.RGBA, x, y int) bool {
+	w, h := img.Bounds().Dx(), img.Bounds().Dy()
+	if x >= w || y >= h {
+		return false
+	}
+	x0, y0 := max(x-1, 0), max(y-1, 0)
+	x2, y2 := min(x+1, w-1), min(y+1, h-1)
+
+	zeroes := 0
+	if x == x0 || x == x2 || y == y0 || y == y2 {
+		zeroes = 1
+	}
+	center := img.RGBAAt(x, y)
+	for ny := y0; ny <= y2; ny++ {
+		for nx := x0; nx <= x2; nx++ {
+			if nx == x && ny == y {
+				continue
+			}
+			if img.RGBAAt(nx, ny) == center {
+				zeroes++
+			}
+			if zeroes > 2 {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// findRegions объединяет отличающиеся пиксели в прямоугольные области
+func findRegions(mask []bool, width, height int) []DiffRegion {
+	cols := (width + regionCellSize - 1) / regionCellSize
+	rows := (height + regionCellSize - 1) / regionCellSize
+
+	// Границы отличий внутри каждой ячейки
+	cells := make([]image.Rectangle, cols*rows)
+	marked := make([]bool, cols*rows)
+	for y := 0; y < height; y++ {
+		for x := 0; x < width; x++ {
+			if !mask[y*width+x] {
+				continue
+			}
+			i := (y/regionCellSize)*cols + x/regionCellSize
+			px := image.Rect(x, y, x+1, y+1)
+			if marked[i] {
+				cells[i] = cells[i].Union(px)
+			} else {
+				cells[i], marked[i] = px, true
+			}
+		}
+	}
+
+	// Соседние (включая диагональ) ячейки объединяются в одну область
+	regions := make([]DiffRegion, 0)
+	visited := make([]bool, cols*rows)
+	for start := range marked {
+		if !marked[start] || visited[start] {
+			continue
+		}
+		bounds := cells[start]
+		stack := []int{start}
+		visited[start] = true
+		for len(stack) > 0 {
+			i := stack[len(stack)-1]
+			stack = stack[:len(stack)-1]
+			bounds = bounds.Union(cells[i])
+			cx, cy := i%cols, i/cols
+			for dy := -1; dy <= 1; dy++ {
+				for dx := -1; dx <= 1; dx++ {
+					nx, ny := cx+dx, cy+dy
+					if nx < 0 || ny < 0 || nx >= cols || ny >= rows {
+						continue
+					}
+					n := ny*cols + nx
+					if marked[n] && !visited[n] {
+						visited[n] = true
+						stack = append(stack, n)
+					}
+				}
+			}
+		}
+		regions = append(regions, DiffRegion{
+			X:      bounds.Min.X,
+			Y:      bounds.Min.Y,
+			Width:  bounds.Dx(),
+			Height: bounds.Dy(),
+		})
+	}
+
+	return regions
+}
@@ -0,0 +1,108 @@
+package service
+
+import (
+	"bytes"
+	"errors"
+	"image"
+	"image/color"
+	"image/draw"
+	"image/png"
+	"testing"
+)
+
+// pngImage белое изображение с залитыми черным прямоугольниками
+func pngImage(t *testing.T, width, height int, rects ...image.Rectangle) []byte {
+	t.Helper()
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	for _, r := range rects {
+		draw.Draw(img, r, image.Black, image.Point{}, draw.Src)
+	}
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		t.Fatal(err)
+	}
+	return buf.Bytes()
+}
+
+func TestCompare(t *testing.T) {
+	d := NewImageDiff()
+	opts := DiffOptions{Threshold: 0.1}
+
+	same := pngImage(t, 40, 30)
+	res, err := d.Compare(same, same, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.MismatchedPixels != 0 || len(res.Regions) != 0 || res.TotalPixels != 1200 {
+		t.Errorf("identical images: %+v", res)
+	}
+
+	changed := pngImage(t, 40, 30, image.Rect(10, 10, 14, 14))
+	res, err = d.Compare(same, changed, opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.MismatchedPixels != 16 || res.MismatchPercentage != 16*100.0/1200 {
+		t.Errorf("mismatched = %d (%v%%), want 16", res.MismatchedPixels, res.MismatchPercentage)
+	}
+	if len(res.Regions) != 1 {
+		t.Fatalf("regions = %+v, want 1", res.Regions)
+	}
+	if r := res.Regions[0]; !image.Rect(r.X, r.Y, r.X+r.Width, r.Y+r.Height).In(image.Rect(8, 8, 16, 16)) {
+		t.Errorf("region %+v does not cover the change tightly", r)
+	}
+	out, err := png.Decode(bytes.NewReader(res.Image))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := color.RGBAModel.Convert(out.At(11, 11)); got != diffColor {
+		t.Errorf("changed pixel = %v, want %v", got, diffColor)
+	}
+
+	// Пиксели за пределами меньшего изображения считаются отличающимися
+	res, err = d.Compare(pngImage(t, 40, 30), pngImage(t, 40, 20), opts)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Height != 30 || res.MismatchedPixels != 400 {
+		t.Errorf("size mismatch: height %d, mismatched %d, want 30 and 400", res.Height, res.MismatchedPixels)
+	}
+}
+
+func TestCompareLimits(t *testing.T) {
+	d := NewImageDiff()
+	img := pngImage(t, 40, 30)
+
+	_, err := d.Compare(img, img, DiffOptions{MaxWidth: 20})
+	var limitErr *LimitError
+	if !errors.As(err, &limitErr) || limitErr.Limit != "max_viewport_width" {
+		t.Errorf("wide image: %v, want max_viewport_width limit error", err)
+	}
+	_, err = d.Compare(img, img, DiffOptions{MaxHeight: 20})
+	if !errors.As(err, &limitErr) || limitErr.Limit != "max_full_page_height" {
+		t.Errorf("tall image: %v, want max_full_page_height limit error", err)
+	}
+	if _, err := d.Compare([]byte("not an image"), img, DiffOptions{}); err == nil {
+		t.Error("broken baseline is accepted")
+	}
+}
+
+func TestDiffOptionsValidate(t *testing.T) {
+	tests := []struct {
+		opts DiffOptions
+		ok   bool
+	}{
+		{DiffOptions{}, true},
+		{DiffOptions{Threshold: 1, AATolerance: 8}, true},
+		{DiffOptions{Threshold: -0.1}, false},
+		{DiffOptions{Threshold: 1.5}, false},
+		{DiffOptions{AATolerance: -1}, false},
+		{DiffOptions{AATolerance: 9}, false},
+	}
+	for _, tt := range tests {
+		if err := tt.opts.Validate(); (err == nil) != tt.ok {
+			t.Errorf("Validate(%+v) = %v, want ok %v", tt.opts, err, tt.ok)
+		}
+	}
+}
@@ -0,0 +1,152 @@
+package service
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"github.com/playwright-community/playwright-go"
+	"net/url"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// Способы загрузки html в браузер
+const (
+	DocumentFile = "file" // Временный файл по адресу file://
+	DocumentHTTP = "http" // Документ отдается по http-адресу через перехват запросов браузера
+)
+
+// documentServer отдает документ запроса и файлы набора по http-адресу через page.Route.
+// Без BaseURL документ живет на синтетическом origin по уникальному пути, прочие запросы к нему получают 404.
+// С BaseURL документ подменяет страницу по этому адресу, относительные ссылки, которых нет в наборе, уходят на реальный сайт
+type documentServer struct {
+	url     string // Адрес документа
+	root    string // Адрес, под которым отдаются файлы набора
+	origin  string // Синтетический origin, все запросы к которому перехватываются
+	html    []byte
+	entry   string // Файл документа в наборе, пусто - набора нет
+	dir     string // Директория набора, соответствующая root
+	proxied bool
+}
+
+func newDocumentServer(html string, opts ScreenshotOptions) (*documentServer, error) {
+	d := &documentServer{html: []byte(html)}
+
+	entry := DefaultBundleEntry
+	if opts.Bundle != nil {
+		entry = filepath.ToSlash(opts.Bundle.Entry)
+		d.entry = filepath.Join(opts.Bundle.Dir, opts.Bundle.Entry)
+		d.dir = filepath.Dir(d.entry)
+	}
+
+	if opts.BaseURL != "" {
+		base, err := ParseBaseURL(opts.BaseURL)
+		if err != nil {
+			return nil, err
+		}
+		base.Fragment = ""
+		d.url = base.String()
+		base.RawQuery = ""
+		base.Path = base.Path[:strings.LastIndex(base.Path, "/")+1]
+		d.root = base.String()
+		d.proxied = true
+		return d, nil
+	}
+
+	origin, err := ParseBaseURL(opts.DocumentOrigin)
+	if err != nil {
+		return nil, fmt.Errorf("invalid document origin: %w", err)
+	}
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return nil, fmt.Errorf("failed to generate document path: %w", err)
+	}
+	// Набор целиком доступен под уникальным путем, ссылки вида ../css/a.css тоже работают
+	d.origin = origin.Scheme + "://" + origin.Host + "/"
+	d.root = d.origin + hex.EncodeToString(id) + "/"
+	d.url = d.root + entry
+	if opts.Bundle != nil {
+		d.dir = opts.Bundle.Dir
+	}
+	return d, nil
+}
+
+// match определяет, какие запросы страницы перехватываются
+func (d *documentServer) match(rawURL string) bool {
+	if d.proxied {
+		return stripFragment(rawURL) == d.url || (d.entry != "" && strings.HasPrefix(rawURL, d.root))
+	}
+	return strings.HasPrefix(rawURL, d.origin)
+}
+
+// handle отвечает на перехваченный запрос документом или файлом набора
+func (d *documentServer) handle(route playwright.Route) {
+	rawURL := route.Request().URL()
+	if stripFragment(rawURL) == d.url {
+		// Документ из набора отдаем файлом, иначе html запроса
+		if d.entry != "" {
+			_ = route.Fulfill(playwright.RouteFulfillOptions{Path: playwright.String(d.entry)})
+			return
+		}
+		_ = route.Fulfill(playwright.RouteFulfillOptions{
+			Status:      playwright.Int(200),
+			ContentType: playwright.String("text/html; charset=utf-8"),
+			Body:        d.html,
+		})
+		return
+	}
+
+	if file, ok := d.file(rawURL); ok {
+		_ = route.Fulfill(playwright.RouteFulfillOptions{Path: playwright.String(file)})
+		return
+	}
+	if d.proxied {
+		_ = route.Continue()
+		return
+	}
+	_ = route.Fulfill(playwright.RouteFulfillOptions{Status: playwright.Int(404)})
+}
+
+// file путь файла набора по адресу запроса
+func (d *documentServer) file(rawURL string) (string, bool) {
+	if d.entry == "" || !strings.HasPrefix(rawURL, d.root) {
+		return "", false
+	}
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return "", false
+	}
+	rootURL, _ := url.Parse(d.root)
+	rel := strings.TrimPrefix(path.Clean(u.Path), path.Clean(rootURL.Path))
+	rel = strings.TrimPrefix(rel, "/")
+	if rel == "" || !filepath.IsLocal(filepath.FromSlash(rel)) {
+		return "", false
+	}
+	file := filepath.Join(d.dir, filepath.FromSlash(rel))
+	if info, err := os.Stat(file); err != nil || !info.Mode().IsRegular() {
+		return "", false
+	}
+	return file, true
+}
+
+// ParseBaseURL проверяет, что адрес абсолютный http(s)
+func ParseBaseURL(raw string) (*url.URL, error) {
+	u, err := url.Parse(raw)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return nil, fmt.Errorf("%q must be an absolute http or https url", raw)
+	}
+	u.Host = strings.ToLower(u.Host)
+	if u.Path == "" {
+		u.Path = "/"
+	}
+	return u, nil
+}
+
+func stripFragment(rawURL string) string {
+	if i := strings.IndexByte(rawURL, '#'); i >= 0 {
+		return rawURL[:i]
+	}
+	return rawURL
+}
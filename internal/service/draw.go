@@ -0,0 +1,126 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/jpeg"
+	"image/png"
+	"screenshoter/config"
+)
+
+// drawSelection рисует рамку вокруг области в заданном стиле
+func drawSelection(img draw.Image, rect image.Rectangle, style *SelectionStyle) error {
+	c, err := config.ParseColor(style.BorderColor)
+	if err != nil {
+		return err
+	}
+	c.A = uint8(float64(c.A) * clamp(style.Opacity, 0, 1))
+
+	width := style.BorderWidth
+	if width <= 0 {
+		width = 1
+	}
+
+	// Длина штриха и промежутка для пунктирных рамок
+	dash, gap := 0, 0
+	switch style.BorderStyle {
+	case "dashed":
+		dash, gap = width*3, width*2
+	case "dotted":
+		dash, gap = width, width
+	}
+	visible := func(offset int) bool {
+		return dash == 0 || offset%(dash+gap) < dash
+	}
+
+	src := image.NewUniform(c)
+	plot := func(x, y int) {
+		p := image.Pt(x, y)
+		if p.In(img.Bounds()) {
+			draw.Draw(img, image.Rect(x, y, x+1, y+1), src, image.Point{}, draw.Over)
+		}
+	}
+
+	// Рамка рисуется внутрь области, как при box-sizing: border-box
+	for w := 0; w < width; w++ {
+		top, bottom := rect.Min.Y+w, rect.Max.Y-1-w
+		left, right := rect.Min.X+w, rect.Max.X-1-w
+		if top > bottom || left > right {
+			break
+		}
+		for x := left; x <= right; x++ {
+			if visible(x - rect.Min.X) {
+				plot(x, top)
+				if bottom != top {
+					plot(x, bottom)
+				}
+			}
+		}
+		for y := top + 1; y < bottom; y++ {
+			if visible(y - rect.Min.Y) {
+				plot(left, y)
+				if right != left {
+					plot(right, y)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+func clamp(v, lo, hi float64) float64 {
+	if v < lo {
+		return lo
+	}
+	if v > hi {
+		return hi
+	}
+	return v
+}
+
+// dimOutside затемняет изображение вне прямоугольников holes с непрозрачностью opacity
+func dimOutside(img *image.RGBA, holes []image.Rectangle, opacity float64) {
+	keep := 1 - clamp(opacity, 0, 1)
+	b := img.Bounds()
+	for y := b.Min.Y; y < b.Max.Y; y++ {
+		for x := b.Min.X; x < b.Max.X; x++ {
+			p := image.Pt(x, y)
+			inside := false
+			for _, h := range holes {
+				if p.In(h) {
+					inside = true
+					break
+				}
+			}
+			if inside {
+				continue
+			}
+			i := img.PixOffset(x, y)
+			for c := 0; c < 3; c++ {
+				img.Pix[i+c] = uint8(float64(img.Pix[i+c]) * keep)
+			}
+		}
+	}
+}
+
+// encodeImage кодирует изображение в формат opts: png или jpeg с качеством opts.Quality
+func encodeImage(img image.Image, opts ScreenshotOptions) ([]byte, error) {
+	var buf bytes.Buffer
+	if opts.OutputType() == "jpeg" {
+		quality := jpeg.DefaultQuality
+		if opts.Quality != nil {
+			quality = *opts.Quality
+		}
+		if err := jpeg.Encode(&buf, img, &jpeg.Options{Quality: quality}); err != nil {
+			return nil, fmt.Errorf("failed to encode image: %w", err)
+		}
+		return buf.Bytes(), nil
+	}
+	if err := png.Encode(&buf, img); err != nil {
+		return nil, fmt.Errorf("failed to encode image: %w", err)
+	}
+	return buf.Bytes(), nil
+}
@@ -0,0 +1,66 @@
+package service
+
+import (
+	"image"
+	"image/color"
+	"image/draw"
+	"testing"
+)
+
+func whiteImage(width, height int) *image.RGBA {
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+	draw.Draw(img, img.Bounds(), image.White, image.Point{}, draw.Src)
+	return img
+}
+
+func TestDrawSelection(t *testing.T) {
+	red := color.RGBA{R: 255, A: 255}
+	white := color.RGBA{R: 255, G: 255, B: 255, A: 255}
+
+	img := whiteImage(20, 20)
+	style := &SelectionStyle{BorderColor: "red", BorderWidth: 2, BorderStyle: "solid", Opacity: 1}
+	if err := drawSelection(img, image.Rect(5, 5, 15, 15), style); err != nil {
+		t.Fatal(err)
+	}
+	// Рамка рисуется внутрь области
+	for _, p := range []image.Point{{5, 5}, {6, 10}, {14, 14}, {10, 13}} {
+		if got := img.RGBAAt(p.X, p.Y); got != red {
+			t.Errorf("border pixel %v = %v, want red", p, got)
+		}
+	}
+	for _, p := range []image.Point{{4, 4}, {15, 10}, {7, 7}, {10, 10}} {
+		if got := img.RGBAAt(p.X, p.Y); got != white {
+			t.Errorf("pixel %v = %v, want white", p, got)
+		}
+	}
+
+	// Пунктир: штрих 3 * ширина, промежуток 2 * ширина
+	img = whiteImage(20, 20)
+	style = &SelectionStyle{BorderColor: "red", BorderWidth: 1, BorderStyle: "dashed", Opacity: 1}
+	if err := drawSelection(img, image.Rect(0, 0, 20, 20), style); err != nil {
+		t.Fatal(err)
+	}
+	for x, want := range []bool{true, true, true, false, false, true} {
+		if got := img.RGBAAt(x, 0) == red; got != want {
+			t.Errorf("dashed pixel (%d, 0) drawn = %v, want %v", x, got, want)
+		}
+	}
+
+	// Прозрачность смешивается с фоном
+	img = whiteImage(10, 10)
+	style = &SelectionStyle{BorderColor: "#000000", BorderWidth: 1, BorderStyle: "solid", Opacity: 0.5}
+	if err := drawSelection(img, img.Bounds(), style); err != nil {
+		t.Fatal(err)
+	}
+	if got := img.RGBAAt(0, 0); got.R < 120 || got.R > 135 {
+		t.Errorf("half transparent border = %v, want gray", got)
+	}
+
+	// Область за пределами изображения обрезается без паники
+	if err := drawSelection(whiteImage(10, 10), image.Rect(-5, -5, 30, 30), style); err != nil {
+		t.Fatal(err)
+	}
+	if err := drawSelection(whiteImage(10, 10), img.Bounds(), &SelectionStyle{BorderColor: "rebeccapurple"}); err == nil {
+		t.Error("unknown color is accepted")
+	}
+}
@@ -0,0 +1,102 @@
+package service
+
+import (
+	"fmt"
+	"regexp"
+	"time"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Geolocation координаты, которые получит страница через navigator.geolocation
+type Geolocation struct {
+	Latitude  float64 `json:"latitude"`  // Широта (-90 - 90)
+	Longitude float64 `json:"longitude"` // Долгота (-180 - 180)
+	Accuracy  float64 `json:"accuracy"`  // Точность в метрах
+}
+
+// Emulation настройки пользователя, которые эмулирует контекст браузера
+type Emulation struct {
+	ColorScheme    string       `json:"color_scheme,omitempty"`    // light, dark или no-preference
+	ReducedMotion  string       `json:"reduced_motion,omitempty"`  // reduce или no-preference
+	Locale         string       `json:"locale,omitempty"`          // Язык и регион, например ru-RU
+	TimezoneID     string       `json:"timezone_id,omitempty"`     // Часовой пояс IANA, например Europe/Moscow
+	Geolocation    *Geolocation `json:"geolocation,omitempty"`     // Координаты, разрешение geolocation выдается автоматически
+	AcceptLanguage string       `json:"accept_language,omitempty"` // Заголовок Accept-Language
+	Media          string       `json:"media,omitempty"`           // screen или print
+}
+
+// localePattern тег языка BCP 47: ru, en-US, zh-Hant-TW
+var localePattern = regexp.MustCompile(`^[A-Za-z]{2,3}(-[A-Za-z0-9]{2,8})*$`)
+
+// Validate проверяет значения эмуляции
+func (e Emulation) Validate() error {
+	switch e.ColorScheme {
+	case "", "light", "dark", "no-preference":
+	default:
+		return fmt.Errorf("color_scheme must be light, dark or no-preference")
+	}
+	switch e.ReducedMotion {
+	case "", "reduce", "no-preference":
+	default:
+		return fmt.Errorf("reduced_motion must be reduce or no-preference")
+	}
+	switch e.Media {
+	case "", "screen", "print":
+	default:
+		return fmt.Errorf("media must be screen or print")
+	}
+	if e.Locale != "" && !localePattern.MatchString(e.Locale) {
+		return fmt.Errorf("locale %q is not a valid language tag", e.Locale)
+	}
+	if e.TimezoneID != "" {
+		if _, err := time.LoadLocation(e.TimezoneID); err != nil || e.TimezoneID == "Local" {
+			return fmt.Errorf("timezone_id %q is not a valid IANA time zone", e.TimezoneID)
+		}
+	}
+	if g := e.Geolocation; g != nil {
+		if g.Latitude < -90 || g.Latitude > 90 || g.Longitude < -180 || g.Longitude > 180 || g.Accuracy < 0 {
+			return fmt.Errorf("geolocation must have latitude -90..90, longitude -180..180 and non-negative accuracy")
+		}
+	}
+	return nil
+}
+
+// contextOptions настройки контекста браузера
+func (e Emulation) contextOptions() playwright.BrowserNewContextOptions {
+	var o playwright.BrowserNewContextOptions
+	if e.ColorScheme != "" {
+		o.ColorScheme = (*playwright.ColorScheme)(&e.ColorScheme)
+	}
+	if e.ReducedMotion != "" {
+		o.ReducedMotion = (*playwright.ReducedMotion)(&e.ReducedMotion)
+	}
+	if e.Locale != "" {
+		o.Locale = playwright.String(e.Locale)
+	}
+	if e.TimezoneID != "" {
+		o.TimezoneId = playwright.String(e.TimezoneID)
+	}
+	if g := e.Geolocation; g != nil {
+		o.Geolocation = &playwright.Geolocation{
+			Latitude:  g.Latitude,
+			Longitude: g.Longitude,
+			Accuracy:  playwright.Float(g.Accuracy),
+		}
+		o.Permissions = []string{"geolocation"}
+	}
+	if e.AcceptLanguage != "" {
+		o.ExtraHttpHeaders = map[string]string{"Accept-Language": e.AcceptLanguage}
+	}
+	return o
+}
+
+// emulateMedia переключает тип носителя страницы (screen или print)
+func (e Emulation) emulateMedia(page playwright.Page) error {
+	if e.Media == "" {
+		return nil
+	}
+	return page.EmulateMedia(playwright.PageEmulateMediaOptions{
+		Media: (*playwright.Media)(&e.Media),
+	})
+}
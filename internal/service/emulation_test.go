@@ -0,0 +1,32 @@
+package service
+
+import "testing"
+
+func TestEmulationValidate(t *testing.T) {
+	tests := []struct {
+		name string
+		e    Emulation
+		ok   bool
+	}{
+		{"empty", Emulation{}, true},
+		{"full", Emulation{ColorScheme: "dark", ReducedMotion: "reduce", Locale: "zh-Hant-TW", TimezoneID: "Europe/Moscow",
+			Geolocation: &Geolocation{Latitude: -90, Longitude: 180, Accuracy: 10}, Media: "print"}, true},
+		{"utc", Emulation{TimezoneID: "UTC"}, true},
+		{"color scheme", Emulation{ColorScheme: "night"}, false},
+		{"reduced motion", Emulation{ReducedMotion: "none"}, false},
+		{"media", Emulation{Media: "tv"}, false},
+		{"locale", Emulation{Locale: "ru_RU"}, false},
+		{"unknown timezone", Emulation{TimezoneID: "Mars/Olympus"}, false},
+		{"local timezone", Emulation{TimezoneID: "Local"}, false},
+		{"latitude", Emulation{Geolocation: &Geolocation{Latitude: 91}}, false},
+		{"longitude", Emulation{Geolocation: &Geolocation{Longitude: -181}}, false},
+		{"accuracy", Emulation{Geolocation: &Geolocation{Accuracy: -1}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := tt.e.Validate(); (err == nil) != tt.ok {
+				t.Errorf("Validate() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
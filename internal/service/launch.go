@@ -0,0 +1,89 @@
+package service
+
+import (
+	"fmt"
+	"maps"
+	"screenshoter/config"
+	"slices"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Proxy прокси-сервер для запросов браузера
+type Proxy struct {
+	Server   string `json:"server"`             // http://host:port, https://host:port или socks5://host:port
+	Bypass   string `json:"bypass,omitempty"`   // Домены без прокси через запятую: .example.com, localhost
+	Username string `json:"username,omitempty"` // Логин прокси с авторизацией
+	Password Secret `json:"password,omitempty"`
+}
+
+// Validate проверяет прокси по правилам прокси из конфигурации, сервер обязателен
+func (p *Proxy) Validate() error {
+	if p.Server == "" {
+		return fmt.Errorf("proxy server is required")
+	}
+	return config.Proxy{Server: p.Server, Bypass: p.Bypass, Username: p.Username, Password: string(p.Password)}.Validate()
+}
+
+// LaunchPreset настройки запуска браузера, заданные оператором. Запрос выбирает пресет по имени,
+// поэтому клиент не может передать браузеру произвольные флаги
+type LaunchPreset struct {
+	Browser            BrowserType // Браузер пресета, пусто - любой
+	Channel            string      // Канал chromium: chrome, msedge, chrome-beta, ...
+	ExecutablePath     string      // Путь к исполняемому файлу браузера
+	Args               []string    // Дополнительные аргументы командной строки
+	Proxy              *Proxy      // Прокси пресета важнее прокси запроса, ключа и сервера, пустой - без прокси
+	IgnoreHTTPSErrors  bool        // Не проверять сертификаты https
+	JavaScriptDisabled bool        // Отключить JavaScript страницы
+}
+
+// presetNames имена пресетов по алфавиту
+func presetNames(presets map[string]LaunchPreset) []string {
+	return slices.Sorted(maps.Keys(presets))
+}
+
+// launchOptions параметры запуска браузера из пресета
+func (p *LaunchPreset) launchOptions() playwright.BrowserTypeLaunchOptions {
+	var opts playwright.BrowserTypeLaunchOptions
+	if p == nil {
+		return opts
+	}
+	opts.Args = p.Args
+	if p.Channel != "" {
+		opts.Channel = playwright.String(p.Channel)
+	}
+	if p.ExecutablePath != "" {
+		opts.ExecutablePath = playwright.String(p.ExecutablePath)
+	}
+	opts.Proxy = p.Proxy.playwright()
+	return opts
+}
+
+// contextOptions дополняет параметры контекста настройками пресета
+func (p *LaunchPreset) contextOptions(opts *playwright.BrowserNewContextOptions) {
+	if p == nil {
+		return
+	}
+	if p.IgnoreHTTPSErrors {
+		opts.IgnoreHttpsErrors = playwright.Bool(true)
+	}
+	if p.JavaScriptDisabled {
+		opts.JavaScriptEnabled = playwright.Bool(false)
+	}
+}
+
+// playwright прокси в формате playwright, nil - без прокси
+func (p *Proxy) playwright() *playwright.Proxy {
+	if p == nil || p.Server == "" {
+		return nil
+	}
+	proxy := &playwright.Proxy{Server: p.Server}
+	if p.Bypass != "" {
+		proxy.Bypass = playwright.String(p.Bypass)
+	}
+	if p.Username != "" {
+		proxy.Username = playwright.String(p.Username)
+		proxy.Password = playwright.String(string(p.Password))
+	}
+	return proxy
+}
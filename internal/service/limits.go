@@ -0,0 +1,127 @@
+package service
+
+import (
+	"fmt"
+	"slices"
+	"time"
+)
+
+// renderTimeoutMargin запас времени на запуск браузера и создание скриншота сверх таймаута загрузки
+const renderTimeoutMargin = 15 * time.Second
+
+// Limits ограничения параметров скриншота, задаваемые оператором
+type Limits struct {
+	MaxViewportWidth  int           // Максимальная ширина viewport (px), 0 - без ограничения
+	MaxViewportHeight int           // Максимальная высота viewport (px), 0 - без ограничения
+	MaxFullPageHeight int           // Максимальная высота страницы при FullPage (px), 0 - без ограничения
+	MaxTimeout        float64       // Максимальный таймаут загрузки (мс), 0 - без ограничения
+	AllowedTypes      []string      // Разрешенные форматы, пусто - все
+	AllowedBrowsers   []BrowserType // Разрешенные браузеры, пусто - все
+	MaxActions        int           // Максимальное число действий со страницей, 0 - без ограничения
+	MaxScrollSteps    int           // Максимальное число шагов прокрутки scroll_container, 0 - без ограничения
+
+	MaxAnimationDuration float64 // Максимальная длительность gif и webm (мс), 0 - без ограничения
+	MaxFrameRate         int     // Максимальная частота кадров gif, 0 - без ограничения
+	MaxAnimationSize     int     // Максимальный размер gif и webm (байт), 0 - без ограничения
+
+	LaunchPresets       map[string]LaunchPreset // Пресеты запуска браузера по имени
+	DefaultLaunchPreset string                  // Пресет для запросов без launch_preset, если подходит браузер
+}
+
+// LimitError превышено ограничение сервера
+type LimitError struct {
+	Limit string `json:"limit"` // Название нарушенного ограничения
+	Value any    `json:"value"` // Запрошенное значение
+	Max   any    `json:"max"`   // Допустимое значение
+}
+
+func (e *LimitError) Error() string {
+	return fmt.Sprintf("%s exceeded: requested %v, allowed %v", e.Limit, e.Value, e.Max)
+}
+
+// Check проверяет параметры скриншота на соответствие ограничениям
+func (l Limits) Check(opts ScreenshotOptions) error {
+	if len(l.AllowedBrowsers) > 0 && !slices.Contains(l.AllowedBrowsers, opts.Browser) {
+		return &LimitError{Limit: "allowed_browsers", Value: opts.Browser, Max: l.AllowedBrowsers}
+	}
+	switch opts.Type {
+	case "", "png", "jpeg", "jpg", TypeGIF, TypeWebM:
+	default:
+		return fmt.Errorf("type %q must be png, jpeg, gif or webm", opts.Type)
+	}
+	// jpg и jpeg - один формат, поэтому сравниваются итоговые форматы
+	if len(l.AllowedTypes) > 0 && !slices.ContainsFunc(l.AllowedTypes, func(t string) bool {
+		return ScreenshotOptions{Type: t}.OutputType() == opts.OutputType()
+	}) {
+		return &LimitError{Limit: "allowed_types", Value: opts.Type, Max: l.AllowedTypes}
+	}
+	if opts.Viewport != nil {
+		if opts.Viewport.Width <= 0 || opts.Viewport.Height <= 0 {
+			return fmt.Errorf("viewport width and height must be positive")
+		}
+		if l.MaxViewportWidth > 0 && opts.Viewport.Width > l.MaxViewportWidth {
+			return &LimitError{Limit: "max_viewport_width", Value: opts.Viewport.Width, Max: l.MaxViewportWidth}
+		}
+		if l.MaxViewportHeight > 0 && opts.Viewport.Height > l.MaxViewportHeight {
+			return &LimitError{Limit: "max_viewport_height", Value: opts.Viewport.Height, Max: l.MaxViewportHeight}
+		}
+	}
+	if l.MaxTimeout > 0 && (opts.Timeout > l.MaxTimeout || opts.Timeout <= 0) {
+		return &LimitError{Limit: "max_timeout", Value: opts.Timeout, Max: l.MaxTimeout}
+	}
+	if l.MaxActions > 0 && len(opts.Actions) > l.MaxActions {
+		return &LimitError{Limit: "max_actions", Value: len(opts.Actions), Max: l.MaxActions}
+	}
+	if opts.Animated() {
+		if l.MaxAnimationDuration > 0 && opts.animationDuration() > l.MaxAnimationDuration {
+			return &LimitError{Limit: "max_animation_duration", Value: opts.animationDuration(), Max: l.MaxAnimationDuration}
+		}
+		if l.MaxFrameRate > 0 && opts.Type == TypeGIF && opts.frameRate() > l.MaxFrameRate {
+			return &LimitError{Limit: "max_frame_rate", Value: opts.frameRate(), Max: l.MaxFrameRate}
+		}
+	}
+	if _, err := l.Preset(opts); err != nil {
+		return err
+	}
+	for _, a := range opts.Actions {
+		if l.MaxTimeout > 0 && (a.Timeout > l.MaxTimeout || a.Duration > l.MaxTimeout) {
+			return &LimitError{Limit: "max_timeout", Value: max(a.Timeout, a.Duration), Max: l.MaxTimeout}
+		}
+	}
+	return nil
+}
+
+// Apply проверяет параметры и переносит в них ограничения, проверяемые при рендере
+func (l Limits) Apply(opts *ScreenshotOptions) error {
+	if err := l.Check(*opts); err != nil {
+		return err
+	}
+	opts.MaxHeight = l.MaxFullPageHeight
+	opts.MaxScrollSteps = l.MaxScrollSteps
+	opts.MaxAnimationSize = l.MaxAnimationSize
+	opts.Launch, _ = l.Preset(*opts)
+	return nil
+}
+
+// Preset пресет запуска, выбранный в параметрах, или пресет по умолчанию, если подходит браузер; nil - без пресета
+func (l Limits) Preset(opts ScreenshotOptions) (*LaunchPreset, error) {
+	if opts.LaunchPreset != "" {
+		preset, ok := l.LaunchPresets[opts.LaunchPreset]
+		if !ok {
+			return nil, &LimitError{Limit: "launch_presets", Value: opts.LaunchPreset, Max: presetNames(l.LaunchPresets)}
+		}
+		if preset.Browser != "" && preset.Browser != opts.Browser {
+			return nil, &LimitError{Limit: "launch_preset_browser", Value: opts.Browser, Max: preset.Browser}
+		}
+		return &preset, nil
+	}
+	if preset, ok := l.LaunchPresets[l.DefaultLaunchPreset]; ok && (preset.Browser == "" || preset.Browser == opts.Browser) {
+		return &preset, nil
+	}
+	return nil, nil
+}
+
+// RenderTimeout общее время на создание скриншота с учетом таймаутов загрузки, действий, склейки и записи анимации
+func RenderTimeout(opts ScreenshotOptions) time.Duration {
+	return time.Duration(opts.Timeout+actionsDuration(opts.Actions)+stitchDuration(opts)+opts.animationDuration())*time.Millisecond + renderTimeoutMargin
+}
@@ -0,0 +1,112 @@
+package service
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestLimitsCheck(t *testing.T) {
+	limits := Limits{
+		MaxViewportWidth:     1920,
+		MaxViewportHeight:    1080,
+		MaxTimeout:           30000,
+		AllowedTypes:         []string{"png", "jpg", TypeGIF},
+		AllowedBrowsers:      []BrowserType{BrowserChromium, BrowserFirefox},
+		MaxActions:           2,
+		MaxAnimationDuration: 5000,
+		MaxFrameRate:         10,
+		LaunchPresets: map[string]LaunchPreset{
+			"chrome": {Browser: BrowserChromium, Channel: "chrome"},
+			"any":    {},
+		},
+	}
+	base := ScreenshotOptions{Browser: BrowserChromium, Type: "png", Timeout: 10000}
+	viewport := func(width, height int) func(o *ScreenshotOptions) {
+		return func(o *ScreenshotOptions) {
+			o.Viewport = &struct {
+				Width  int `json:"width"`
+				Height int `json:"height"`
+			}{width, height}
+		}
+	}
+
+	tests := []struct {
+		name  string
+		edit  func(o *ScreenshotOptions)
+		limit string // Ожидаемое ограничение, пусто - без ошибки
+		err   bool   // Ошибка параметров, а не ограничения
+	}{
+		{name: "within limits", edit: viewport(1920, 1080)},
+		{name: "jpeg as jpg", edit: func(o *ScreenshotOptions) { o.Type = "jpeg" }},
+		{name: "gif", edit: func(o *ScreenshotOptions) { o.Type, o.Duration, o.FrameRate = TypeGIF, 5000, 10 }},
+		{name: "preset", edit: func(o *ScreenshotOptions) { o.LaunchPreset = "chrome" }},
+		{name: "browser", edit: func(o *ScreenshotOptions) { o.Browser = BrowserWebkit }, limit: "allowed_browsers"},
+		{name: "type", edit: func(o *ScreenshotOptions) { o.Type = TypeWebM }, limit: "allowed_types"},
+		{name: "unknown type", edit: func(o *ScreenshotOptions) { o.Type = "bmp" }, err: true},
+		{name: "viewport width", edit: viewport(1921, 100), limit: "max_viewport_width"},
+		{name: "viewport height", edit: viewport(100, 1081), limit: "max_viewport_height"},
+		{name: "empty viewport", edit: viewport(0, 100), err: true},
+		{name: "timeout", edit: func(o *ScreenshotOptions) { o.Timeout = 30001 }, limit: "max_timeout"},
+		{name: "no timeout", edit: func(o *ScreenshotOptions) { o.Timeout = 0 }, limit: "max_timeout"},
+		{name: "actions", edit: func(o *ScreenshotOptions) { o.Actions = make([]Action, 3) }, limit: "max_actions"},
+		{name: "action timeout", edit: func(o *ScreenshotOptions) { o.Actions = []Action{{Type: "wait", Duration: 40000}} }, limit: "max_timeout"},
+		{name: "animation duration", edit: func(o *ScreenshotOptions) { o.Type, o.Duration = TypeGIF, 6000 }, limit: "max_animation_duration"},
+		{name: "frame rate", edit: func(o *ScreenshotOptions) { o.Type, o.FrameRate = TypeGIF, 30 }, limit: "max_frame_rate"},
+		{name: "unknown preset", edit: func(o *ScreenshotOptions) { o.LaunchPreset = "edge" }, limit: "launch_presets"},
+		{name: "preset browser", edit: func(o *ScreenshotOptions) { o.Browser, o.LaunchPreset = BrowserFirefox, "chrome" }, limit: "launch_preset_browser"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			opts := base
+			tt.edit(&opts)
+			err := limits.Check(opts)
+			var limitErr *LimitError
+			switch {
+			case tt.limit != "":
+				if !errors.As(err, &limitErr) || limitErr.Limit != tt.limit {
+					t.Errorf("Check() = %v, want %s limit error", err, tt.limit)
+				}
+			case tt.err:
+				if err == nil || errors.As(err, &limitErr) {
+					t.Errorf("Check() = %v, want validation error", err)
+				}
+			case err != nil:
+				t.Errorf("Check() = %v", err)
+			}
+		})
+	}
+
+	// Без ограничений проходят любые параметры
+	if err := (Limits{}).Check(ScreenshotOptions{Browser: BrowserWebkit, Type: TypeWebM}); err != nil {
+		t.Errorf("empty limits: %v", err)
+	}
+}
+
+func TestLimitsApply(t *testing.T) {
+	limits := Limits{
+		MaxFullPageHeight:   5000,
+		MaxScrollSteps:      20,
+		MaxAnimationSize:    1 << 20,
+		LaunchPresets:       map[string]LaunchPreset{"chrome": {Browser: BrowserChromium, Channel: "chrome"}},
+		DefaultLaunchPreset: "chrome",
+	}
+	opts := ScreenshotOptions{Browser: BrowserChromium, Type: "png"}
+	if err := limits.Apply(&opts); err != nil {
+		t.Fatal(err)
+	}
+	if opts.MaxHeight != 5000 || opts.MaxScrollSteps != 20 || opts.MaxAnimationSize != 1<<20 {
+		t.Errorf("limits are not applied: %d, %d, %d", opts.MaxHeight, opts.MaxScrollSteps, opts.MaxAnimationSize)
+	}
+	if opts.Launch == nil || opts.Launch.Channel != "chrome" {
+		t.Errorf("default preset is not applied: %+v", opts.Launch)
+	}
+
+	// Пресет по умолчанию для другого браузера не применяется
+	opts = ScreenshotOptions{Browser: BrowserFirefox, Type: "png"}
+	if err := limits.Apply(&opts); err != nil {
+		t.Fatal(err)
+	}
+	if opts.Launch != nil {
+		t.Errorf("preset for chromium is applied to firefox: %+v", opts.Launch)
+	}
+}
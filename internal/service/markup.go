@@ -0,0 +1,120 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"html/template"
+
+	chromahtml "github.com/alecthomas/chroma/v2/formatters/html"
+	"github.com/alecthomas/chroma/v2/styles"
+	"github.com/yuin/goldmark"
+	highlighting "github.com/yuin/goldmark-highlighting/v2"
+	"github.com/yuin/goldmark/extension"
+	goldmarkhtml "github.com/yuin/goldmark/renderer/html"
+)
+
+// Форматы исходного документа
+const (
+	InputHTML     = "html"
+	InputMarkdown = "markdown"
+	InputText     = "text"
+)
+
+// DefaultCodeTheme тема подсветки кода по умолчанию
+const DefaultCodeTheme = "github"
+
+// MarkupStyle оформление страницы, собранной из markdown или текста
+type MarkupStyle struct {
+	Stylesheet string // CSS страницы, пусто - встроенный
+	CodeTheme  string // Тема подсветки кода chroma, например github или monokai
+}
+
+// defaultStylesheet оформление страницы по умолчанию
+const defaultStylesheet = `
+body { margin: 0; padding: 32px; background: #fff; color: #1f2328;
+       font: 16px/1.5 -apple-system, "Segoe UI", Helvetica, Arial, sans-serif; }
+h1, h2 { border-bottom: 1px solid #d1d9e0; padding-bottom: .3em; }
+a { color: #0969da; }
+code, pre { font-family: ui-monospace, SFMono-Regular, Menlo, Consolas, monospace; font-size: 85%; }
+:not(pre) > code { background: #eff1f3; border-radius: 6px; padding: .2em .4em; }
+pre { background: #f6f8fa; border-radius: 6px; padding: 16px; overflow: auto; }
+pre.text { background: none; padding: 0; margin: 0; font-size: 14px; white-space: pre-wrap; word-wrap: break-word; }
+blockquote { margin: 0; padding: 0 1em; color: #59636e; border-left: .25em solid #d1d9e0; }
+table { border-collapse: collapse; }
+th, td { border: 1px solid #d1d9e0; padding: 6px 13px; }
+img { max-width: 100%; }
+`
+
+// markupPage обертка документа из markdown или текста
+var markupPage = template.Must(template.New("page").Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<style>{{.Stylesheet}}</style>
+</head>
+<body>
+{{.Body}}
+</body>
+</html>
+`))
+
+// CodeThemeExists есть ли тема подсветки кода с таким именем
+func CodeThemeExists(name string) bool {
+	_, ok := styles.Registry[name]
+	return ok
+}
+
+// MarkdownToHTML преобразует markdown (GFM: таблицы, списки задач, зачеркивание)
+// в html-страницу с подсветкой блоков кода. Html внутри markdown сохраняется
+func MarkdownToHTML(src string, style MarkupStyle) (string, error) {
+	theme := style.CodeTheme
+	if theme == "" {
+		theme = DefaultCodeTheme
+	}
+	if !CodeThemeExists(theme) {
+		return "", fmt.Errorf("unknown code theme %q", theme)
+	}
+
+	md := goldmark.New(
+		goldmark.WithExtensions(
+			extension.GFM,
+			highlighting.NewHighlighting(
+				highlighting.WithStyle(theme),
+				highlighting.WithFormatOptions(chromahtml.WithClasses(false)),
+			),
+		),
+		goldmark.WithRendererOptions(goldmarkhtml.WithUnsafe()),
+	)
+
+	var body bytes.Buffer
+	if err := md.Convert([]byte(src), &body); err != nil {
+		return "", fmt.Errorf("failed to convert markdown: %w", err)
+	}
+	return markupDocument(template.HTML(body.String()), style)
+}
+
+// TextToHTML оборачивает текст в html-страницу, переносы строк и пробелы сохраняются
+func TextToHTML(src string, style MarkupStyle) (string, error) {
+	var body bytes.Buffer
+	body.WriteString(`<pre class="text">`)
+	template.HTMLEscape(&body, []byte(src))
+	body.WriteString(`</pre>`)
+	return markupDocument(template.HTML(body.String()), style)
+}
+
+func markupDocument(body template.HTML, style MarkupStyle) (string, error) {
+	stylesheet := style.Stylesheet
+	if stylesheet == "" {
+		stylesheet = defaultStylesheet
+	}
+
+	var page bytes.Buffer
+	err := markupPage.Execute(&page, struct {
+		Stylesheet template.CSS
+		Body       template.HTML
+	}{template.CSS(stylesheet), body})
+	if err != nil {
+		return "", err
+	}
+	return page.String(), nil
+}
@@ -1,15 +1,35 @@
 package service
 
 import (
+	"context"
 	"crypto/rand"
 	"fmt"
 	"github.com/playwright-community/playwright-go"
 	"os"
 	"path/filepath"
+	"screenshoter/internal/metrics"
 	"screenshoter/pkg/logger"
+	"sync/atomic"
 	"time"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
 )
 
+// tracer спаны этапов рендера
+var tracer = otel.Tracer("screenshoter/internal/service")
+
+// phases этапы рендера, длительность которых попадает в метрику PhaseDuration
+var phases = map[string]string{
+	"browser.launch":       "launch",
+	"page.goto":            "navigate",
+	"page.wait_load_state": "wait",
+	"page.actions":         "actions",
+	"page.screenshot":      "capture",
+}
+
 type BrowserType string
 
 const (
@@ -18,6 +38,20 @@ const (
 	BrowserWebkit   BrowserType = "webkit"
 )
 
+// ParseBrowser определяет браузер по названию, по умолчанию chromium
+func ParseBrowser(value string) (BrowserType, error) {
+	switch value {
+	case "", "chromium", "chrome":
+		return BrowserChromium, nil
+	case "firefox":
+		return BrowserFirefox, nil
+	case "webkit":
+		return BrowserWebkit, nil
+	default:
+		return "", fmt.Errorf("unsupported browser %q", value)
+	}
+}
+
 type Playwright struct {
 	pw  *playwright.Playwright
 	lgr *logger.Logger
@@ -36,63 +70,172 @@ func (p *Playwright) Close() error {
 	return p.pw.Stop()
 }
 
-// Make формирует скриншот из html
-func (p *Playwright) Make(html string, opts ScreenshotOptions) ([]byte, string, error) {
-	if html == "" {
-		return nil, "", fmt.Errorf("html content cannot be empty")
+// Make формирует скриншот из html, набора файлов opts.Bundle или страницы по адресу opts.URL
+func (p *Playwright) Make(ctx context.Context, html string, opts ScreenshotOptions) (_ *Result, err error) {
+	ctx, span := tracer.Start(ctx, "playwright.Make", trace.WithAttributes(
+		attribute.String("screenshot.browser", string(opts.Browser)),
+		attribute.String("screenshot.type", opts.Type),
+		attribute.Bool("screenshot.full_page", opts.FullPage),
+		attribute.String("screenshot.launch_preset", opts.LaunchPreset),
+	))
+	defer func() {
+		endSpan(span, err)
+	}()
+
+	if html == "" && opts.URL == "" && opts.Bundle == nil {
+		return nil, fmt.Errorf("html content cannot be empty")
+	}
+	lgr := logger.FromContext(ctx, p.lgr)
+	// Анимация записывает только окно страницы
+	if opts.Animated() {
+		opts.FullPage = false
 	}
 	// Выбираем браузер в зависимости от параметра
 	var browser playwright.Browser
-	var err error
 
-	switch opts.Browser {
-	case BrowserFirefox:
-		browser, err = p.pw.Firefox.Launch()
-	case BrowserWebkit:
-		browser, err = p.pw.WebKit.Launch()
-	default: // По умолчанию Chromium
-		browser, err = p.pw.Chromium.Launch()
+	// Аргументы и канал задаются только пресетом оператора, его прокси важнее прокси запроса
+	launchOpts := opts.Launch.launchOptions()
+	if opts.Launch == nil || opts.Launch.Proxy == nil {
+		launchOpts.Proxy = opts.Proxy.playwright()
 	}
-
+	err = step(ctx, opts.Browser, "browser.launch", func() error {
+		switch opts.Browser {
+		case BrowserFirefox:
+			browser, err = p.pw.Firefox.Launch(launchOpts)
+		case BrowserWebkit:
+			browser, err = p.pw.WebKit.Launch(launchOpts)
+		default: // По умолчанию Chromium
+			browser, err = p.pw.Chromium.Launch(launchOpts)
+		}
+		return err
+	})
 	if err != nil {
-		return nil, "", fmt.Errorf("could not launch %s browser: %w", opts.Browser, err)
+		return nil, fmt.Errorf("could not launch %s browser: %w", opts.Browser, err)
 	}
+	// Отключение браузера до закрытия считаем падением
+	var closing atomic.Bool
+	browser.OnDisconnected(func(playwright.Browser) {
+		if !closing.Load() {
+			metrics.BrowserCrashes.WithLabelValues(string(opts.Browser), "disconnected").Inc()
+		}
+	})
 	defer func() {
+		closing.Store(true)
 		if closeErr := browser.Close(); closeErr != nil {
-			p.lgr.Warn().Msgf("failed to close browser: %v", closeErr)
+			lgr.Warn().Msgf("failed to close browser: %v", closeErr)
 		}
 	}()
 
-	// Создаем временный файл со случайным именем
-	htmlPath, err := p.createTempHTML(html)
-	if err != nil {
-		return nil, "", err
-	}
-	defer func() {
-		if removeErr := os.Remove(htmlPath); removeErr != nil {
-			p.lgr.Warn().Msgf("failed to remove temp file %s: %v", htmlPath, removeErr)
+	url := opts.URL
+	var doc *documentServer
+	if (html != "" || opts.Bundle != nil) && (opts.DocumentMode == DocumentHTTP || opts.BaseURL != "") {
+		// Документ по http-адресу через перехват запросов
+		if doc, err = newDocumentServer(html, opts); err != nil {
+			return nil, err
 		}
-	}()
+		url = doc.url
+	} else if opts.Bundle != nil {
+		url = opts.Bundle.URL()
+	} else if url == "" {
+		// Создаем временный файл со случайным именем
+		var htmlPath string
+		err := step(ctx, opts.Browser, "tempfile.write", func() (err error) {
+			htmlPath, err = p.createTempHTML(html)
+			return err
+		})
+		if err != nil {
+			return nil, err
+		}
+		defer func() {
+			if removeErr := os.Remove(htmlPath); removeErr != nil {
+				lgr.Warn().Msgf("failed to remove temp file %s: %v", htmlPath, removeErr)
+			}
+		}()
+		url = "file://" + htmlPath
+	}
 
-	page, err := browser.NewPage()
+	// Контекст браузера с эмуляцией настроек пользователя и данными авторизации.
+	// Для отладочных артефактов в контексте дополнительно записываются HAR и трасса
+	contextOpts := opts.Emulation.contextOptions()
+	if err := opts.Credentials.contextOptions(&contextOpts); err != nil {
+		return nil, err
+	}
+	opts.Launch.contextOptions(&contextOpts)
+	// Видео пишется с открытия страницы, поэтому в webm попадает и загрузка
+	if opts.OutputType() == TypeWebM {
+		videoDir, err := os.MkdirTemp("", "screenshot_video_")
+		if err != nil {
+			return nil, fmt.Errorf("failed to create video dir: %w", err)
+		}
+		defer func() {
+			if removeErr := os.RemoveAll(videoDir); removeErr != nil {
+				lgr.Warn().Msgf("failed to remove video dir %s: %v", videoDir, removeErr)
+			}
+		}()
+		contextOpts.RecordVideo = &playwright.RecordVideo{Dir: videoDir, Size: videoSize(opts)}
+	}
+	var recorder *artifactRecorder
+	var browserContext playwright.BrowserContext
+	if opts.DebugArtifacts {
+		if recorder, err = newArtifactRecorder(browser, contextOpts); err != nil {
+			return nil, err
+		}
+		defer func() {
+			if cleanupErr := recorder.cleanup(); cleanupErr != nil {
+				lgr.Warn().Msgf("failed to remove debug artifacts: %v", cleanupErr)
+			}
+		}()
+		browserContext = recorder.context
+	} else {
+		if browserContext, err = browser.NewContext(contextOpts); err != nil {
+			return nil, fmt.Errorf("failed to create browser context: %w", err)
+		}
+		defer func() {
+			if closeErr := browserContext.Close(); closeErr != nil {
+				lgr.Warn().Msgf("failed to close browser context: %v", closeErr)
+			}
+		}()
+	}
+	if err := opts.Credentials.addCookies(browserContext, url); err != nil {
+		return nil, err
+	}
+	// Водяной знак видео добавляется в каждый документ, кадры gif и изображения размечаются после съемки
+	if opts.Watermark != nil && opts.OutputType() == TypeWebM {
+		script, err := watermarkInitScript(opts.Watermark)
+		if err != nil {
+			return nil, err
+		}
+		if err := browserContext.AddInitScript(playwright.Script{Content: playwright.String(script)}); err != nil {
+			return nil, fmt.Errorf("failed to add watermark: %w", err)
+		}
+	}
+	page, err := browserContext.NewPage()
 	if err != nil {
-		return nil, "", err
+		return nil, err
 	}
-	defer func() {
-		if closeErr := page.Close(); closeErr != nil {
-			p.lgr.Warn().Msgf("failed to close page: %v", closeErr)
+	page.OnCrash(func(playwright.Page) {
+		metrics.BrowserCrashes.WithLabelValues(string(opts.Browser), "page_crash").Inc()
+	})
+	diagnostics := &diagnosticsCollector{}
+	diagnostics.attach(page)
+
+	if doc != nil {
+		if err := page.Route(doc.match, doc.handle); err != nil {
+			return nil, fmt.Errorf("failed to serve document: %w", err)
 		}
-	}()
+	}
+	if err := opts.Emulation.emulateMedia(page); err != nil {
+		return nil, fmt.Errorf("failed to emulate media: %w", err)
+	}
 
 	// Устанавливаем размер viewport если указан
 	if opts.Viewport != nil {
 		if err := page.SetViewportSize(opts.Viewport.Width, opts.Viewport.Height); err != nil {
-			return nil, "", err
+			return nil, err
 		}
 	}
 
-	// Загружаем локальный HTML файл
-	url := "file://" + htmlPath
+	// Загружаем страницу
 	gotoOpts := playwright.PageGotoOptions{
 		WaitUntil: playwright.WaitUntilStateNetworkidle,
 	}
@@ -100,25 +243,29 @@ func (p *Playwright) Make(html string, opts ScreenshotOptions) ([]byte, string,
 		gotoOpts.Timeout = playwright.Float(opts.Timeout)
 	}
 
-	if _, err = page.Goto(url, gotoOpts); err != nil {
-		return nil, "", err
+	if err = step(ctx, opts.Browser, "page.goto", func() error {
+		_, err := page.Goto(url, gotoOpts)
+		return err
+	}); err != nil {
+		return nil, err
 	}
 
 	// Ждем загрузки всех ресурсов
-	if err := page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
-		State: playwright.LoadStateNetworkidle,
+	if err = step(ctx, opts.Browser, "page.wait_load_state", func() error {
+		return page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
+			State: playwright.LoadStateNetworkidle,
+		})
 	}); err != nil {
-		return nil, "", err
+		return nil, err
 	}
 
-	// Прокручиваем страницу если нужно
-	if opts.ScrollX != 0 || opts.ScrollY != 0 {
-		_, err := page.Evaluate(fmt.Sprintf("window.scrollTo(%d, %d)", opts.ScrollX, opts.ScrollY))
-		if err != nil {
-			return nil, "", fmt.Errorf("failed to scroll page: %w", err)
+	// Прокрутка и действия со страницей перед скриншотом
+	if actions := opts.actions(); len(actions) > 0 {
+		if err = step(ctx, opts.Browser, "page.actions", func() error {
+			return runActions(ctx, page, actions)
+		}); err != nil {
+			return nil, err
 		}
-		// Ждем завершения прокрутки
-		time.Sleep(100 * time.Millisecond)
 	}
 
 	// Настраиваем параметры скриншота
@@ -130,8 +277,12 @@ func (p *Playwright) Make(html string, opts ScreenshotOptions) ([]byte, string,
 	// Определяем тип и content-type
 	contentType := "image/png"
 
-	switch opts.Type {
-	case "jpeg", "jpg":
+	switch opts.OutputType() {
+	case TypeGIF:
+		contentType = "image/gif"
+	case TypeWebM:
+		contentType = "video/webm"
+	case "jpeg":
 		screenshotType := playwright.ScreenshotTypeJpeg
 		screenshotOpts.Type = screenshotType
 		contentType = "image/jpeg"
@@ -143,88 +294,125 @@ func (p *Playwright) Make(html string, opts ScreenshotOptions) ([]byte, string,
 		screenshotOpts.Type = screenshotType
 	}
 
-	// Если указана область выделения
-	if opts.Selections != nil {
-
-		// Стандартный стиль, если не указан
-		style := opts.SelectionStyle
-		if style == nil {
-			style = &SelectionStyle{
-				BorderColor: "#FF0000",
-				BorderWidth: 2,
-				BorderStyle: "dashed",
-				Opacity:     1.0,
-			}
+	// Рамки выделений. При склейке ScrollContainer выделения рисуются на готовом изображении
+	var drawn []interface{}
+	if len(opts.Selections) > 0 && opts.ScrollContainer == "" {
+		var hidden []int
+		if err = step(ctx, opts.Browser, "page.draw_selections", func() (err error) {
+			drawn, hidden, err = drawSelections(page, opts.Selections, opts.SelectionStyle, opts.FullPage)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+		if len(hidden) > 0 {
+			lgr.Debug().Ints("selections", hidden).Msg("Selections are outside of the captured area")
 		}
+	}
 
-		for i, selection := range opts.Selections {
-			// Проверяем валидность координат
-			if selection.Width <= 0 || selection.Height <= 0 {
-				return nil, "", fmt.Errorf("invalid selection dimensions: width and height must be positive")
-			}
+	// Аннотации и затемнение вокруг нарисованных выделений
+	if (len(opts.Annotations) > 0 || opts.Spotlight) && opts.ScrollContainer == "" {
+		if err = step(ctx, opts.Browser, "page.draw_annotations", func() error {
+			return drawAnnotations(page, opts, drawn)
+		}); err != nil {
+			return nil, err
+		}
+	}
 
-			// Абсолютные координаты на странице
-			absoluteX := selection.X
-			absoluteY := selection.Y
-
-			// Проверяем видимость в текущем viewport
-			visibleX := absoluteX - opts.ScrollX
-			visibleY := absoluteY - opts.ScrollY
-			if visibleX+selection.Width <= 0 || // Полностью слева от viewport
-				visibleY+selection.Height <= 0 || // Полностью сверху от viewport
-				visibleX >= opts.Viewport.Width || // Полностью справа от viewport
-				visibleY >= opts.Viewport.Height { // Полностью снизу от viewport
-				p.lgr.Debug().Msgf("Selection %d is not visible in current viewport", i)
-				continue
-			}
+	// Прерываем рендер, если страница выбросила исключение
+	if opts.FailOnPageError {
+		if d := diagnostics.snapshot(); len(d.PageErrors) > 0 {
+			return nil, &PageError{Diagnostics: d}
+		}
+	}
 
-			// JavaScript код для добавления прямоугольника выделения
-			js := fmt.Sprintf(`
-				(() => {
-					const div = document.createElement('div');
-					div.id = 'selection-rect-%d';
-					div.style.position = 'absolute';
-					div.style.left = '%dpx';
-					div.style.top = '%dpx';
-					div.style.width = '%dpx';
-					div.style.height = '%dpx';
-					div.style.border = '%dpx %s %s';
-					div.style.opacity = '%f';
-					div.style.boxSizing = 'border-box';
-					div.style.zIndex = '2147483647';
-					div.style.pointerEvents = 'none';
-					if (document.body) {
-						document.body.appendChild(div);
-					} else if (document.documentElement) {
-						document.documentElement.appendChild(div);
-					}
-				})()
-			`,
-				i,
-				absoluteX,
-				absoluteY,
-				selection.Width,
-				selection.Height,
-				style.BorderWidth,
-				style.BorderStyle,
-				style.BorderColor,
-				style.Opacity,
-			)
-
-			// Выполняем JavaScript на странице
-			if _, err := page.Evaluate(js); err != nil {
-				return nil, "", fmt.Errorf("failed to draw selection rectangle: %w", err)
-			}
+	// Высота страницы проверяется непосредственно перед снимком: действия могли ее увеличить
+	if opts.FullPage && opts.MaxHeight > 0 && opts.ScrollContainer == "" {
+		height, err := page.Evaluate("Math.max(document.documentElement.scrollHeight, document.body ? document.body.scrollHeight : 0)")
+		if err != nil {
+			return nil, fmt.Errorf("failed to measure page height: %w", err)
+		}
+		if h := toInt(height); h > opts.MaxHeight {
+			return nil, &LimitError{Limit: "max_full_page_height", Value: h, Max: opts.MaxHeight}
 		}
 	}
 
 	// Делаем скриншот в память
-	bytes, err := page.Screenshot(screenshotOpts)
+	var bytes []byte
+	if err = step(ctx, opts.Browser, "page.screenshot", func() (err error) {
+		switch {
+		case opts.ScrollContainer != "":
+			bytes, err = captureScrollContainer(ctx, page, opts)
+		case opts.OutputType() == TypeGIF:
+			bytes, err = captureGIF(ctx, page, opts)
+		case opts.OutputType() == TypeWebM:
+			bytes, err = recordWebM(ctx, page, opts)
+		default:
+			bytes, err = page.Screenshot(screenshotOpts)
+		}
+		return err
+	}); err != nil {
+		return nil, err
+	}
+	if opts.Animated() && opts.MaxAnimationSize > 0 && len(bytes) > opts.MaxAnimationSize {
+		return nil, &LimitError{Limit: "max_animation_size", Value: len(bytes), Max: opts.MaxAnimationSize}
+	}
+	if opts.Watermark != nil && !opts.Animated() {
+		if err = step(ctx, opts.Browser, "image.watermark", func() (err error) {
+			bytes, err = applyWatermark(bytes, opts)
+			return err
+		}); err != nil {
+			return nil, err
+		}
+	}
+	span.SetAttributes(attribute.Int("screenshot.size", len(bytes)))
+	metrics.OutputSize.WithLabelValues(opts.OutputType()).Observe(float64(len(bytes)))
+
+	res := &Result{Image: bytes, ContentType: contentType, Diagnostics: diagnostics.snapshot()}
+	if recorder != nil {
+		if err = step(ctx, opts.Browser, "artifacts.collect", func() (err error) {
+			res.Artifacts, err = recorder.finish()
+			return err
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	return res, nil
+}
+
+// step выполняет этап рендера в отдельном спане
+func step(ctx context.Context, browser BrowserType, name string, fn func() error) error {
+	_, span := tracer.Start(ctx, name)
+	start := time.Now()
+	err := fn()
+	if phase, ok := phases[name]; ok {
+		metrics.PhaseDuration.WithLabelValues(phase, string(browser)).Observe(time.Since(start).Seconds())
+	}
+	endSpan(span, err)
+	return err
+}
+
+// endSpan завершает спан, отмечая ошибку
+func endSpan(span trace.Span, err error) {
 	if err != nil {
-		return nil, "", err
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
 	}
+	span.End()
+}
 
-	return bytes, contentType, nil
+// toInt приводит число, полученное из JavaScript, к int
+func toInt(v interface{}) int {
+	switch n := v.(type) {
+	case int:
+		return n
+	case int64:
+		return int(n)
+	case float64:
+		return int(n)
+	default:
+		return 0
+	}
 }
 
 // createTempHTML создает файл с контентом со случайным именем
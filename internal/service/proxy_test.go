@@ -0,0 +1,82 @@
+package service
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"screenshoter/config"
+	"screenshoter/pkg/logger"
+	"slices"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// proxyRecorder прямой прокси, который сам отвечает на запросы и запоминает запрошенные хосты
+type proxyRecorder struct {
+	mu    sync.Mutex
+	hosts []string
+}
+
+func (p *proxyRecorder) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	p.mu.Lock()
+	p.hosts = append(p.hosts, r.URL.Host)
+	p.mu.Unlock()
+
+	if r.URL.Host == "page.test" && r.URL.Path == "/" {
+		w.Header().Set("Content-Type", "text/html")
+		_, _ = w.Write([]byte(`<h1>proxied</h1><img src="http://assets.test/pixel.png">`))
+		return
+	}
+	http.NotFound(w, r)
+}
+
+func (p *proxyRecorder) seen(host string) bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return slices.Contains(p.hosts, host)
+}
+
+func TestRenderThroughProxy(t *testing.T) {
+	pw, err := NewPlaywright(logger.NewLogger(&config.Config{}))
+	if err != nil {
+		t.Skipf("playwright is not installed: %v", err)
+	}
+	defer pw.Close()
+
+	tests := []struct {
+		name       string
+		bypass     string
+		wantAssets bool // Запрос картинки с assets.test прошел через прокси
+	}{
+		{name: "all requests", wantAssets: true},
+		{name: "bypass list", bypass: "assets.test", wantAssets: false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder := &proxyRecorder{}
+			proxy := httptest.NewServer(recorder)
+			defer proxy.Close()
+
+			_, err := pw.Make(context.Background(), "", ScreenshotOptions{
+				Browser: BrowserChromium,
+				Type:    "png",
+				URL:     "http://page.test/",
+				Timeout: 10000,
+				Proxy:   &Proxy{Server: proxy.URL, Bypass: tt.bypass},
+			})
+			if err != nil && strings.Contains(err.Error(), "could not launch") {
+				t.Skipf("browser is not installed: %v", err)
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !recorder.seen("page.test") {
+				t.Fatalf("page was not requested through the proxy, proxy saw %v", recorder.hosts)
+			}
+			if got := recorder.seen("assets.test"); got != tt.wantAssets {
+				t.Fatalf("assets through proxy = %v, want %v, proxy saw %v", got, tt.wantAssets, recorder.hosts)
+			}
+		})
+	}
+}
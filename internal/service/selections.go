@@ -0,0 +1,187 @@
+package service
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/playwright-community/playwright-go"
+)
+
+// Системы координат выделения
+const (
+	SelectionPage     = "page"     // От левого верхнего угла страницы (по умолчанию)
+	SelectionViewport = "viewport" // От левого верхнего угла окна в момент скриншота
+	SelectionElement  = "element"  // От левого верхнего угла элемента Selector
+)
+
+// defaultSelectionStyle стиль выделения, если он не задан
+var defaultSelectionStyle = SelectionStyle{
+	BorderColor: "#FF0000",
+	BorderWidth: 2,
+	BorderStyle: "dashed",
+	Opacity:     1.0,
+}
+
+// SelectionError выделение нельзя нарисовать
+type SelectionError struct {
+	Index    int    `json:"index"` // Номер выделения, с нуля
+	Selector string `json:"selector,omitempty"`
+	Message  string `json:"message"`
+}
+
+func (e *SelectionError) Error() string {
+	return fmt.Sprintf("selection %d: %s", e.Index, e.Message)
+}
+
+// ValidateSelections проверяет систему координат и размеры выделений
+func ValidateSelections(selections []SelectionArea) error {
+	for i, s := range selections {
+		switch s.Space {
+		case "", SelectionPage, SelectionViewport:
+			if s.Width <= 0 || s.Height <= 0 {
+				return &SelectionError{Index: i, Message: "width and height must be positive"}
+			}
+		case SelectionElement:
+			if s.Selector == "" {
+				return &SelectionError{Index: i, Message: "selector is required for element space"}
+			}
+			if s.Width < 0 || s.Height < 0 {
+				return &SelectionError{Index: i, Selector: s.Selector, Message: "width and height must not be negative"}
+			}
+		default:
+			return &SelectionError{Index: i, Message: "space must be page, viewport or element"}
+		}
+	}
+	return nil
+}
+
+// locateJS функция locate переводит точку и размер item в координаты страницы с учетом системы координат.
+// dx и dy - сдвиг, который нужно добавить к другим точкам того же item. Возвращает null, если элемент не найден
+const locateJS = `const locate = (item) => {
+	let x = item.x || 0, y = item.y || 0, width = item.width || 0, height = item.height || 0;
+	if (item.space === 'viewport') {
+		x += window.scrollX;
+		y += window.scrollY;
+	} else if (item.space === 'element') {
+		const el = document.querySelector(item.selector);
+		if (!el) {
+			return null;
+		}
+		const rect = el.getBoundingClientRect();
+		x += rect.left + window.scrollX;
+		y += rect.top + window.scrollY;
+		width = width || rect.width;
+		height = height || rect.height;
+	}
+	return {x, y, width, height, dx: x - (item.x || 0), dy: y - (item.y || 0)};
+};
+`
+
+// drawSelectionsJS переводит выделения в координаты страницы, обрезает их по снимаемой области
+// (окно или вся страница при fullPage) и добавляет рамки поверх страницы.
+// Для каждого выделения возвращает нарисованный прямоугольник, notFound, если элемент не найден,
+// или hidden, если выделение целиком вне области
+const drawSelectionsJS = `({selections, style, fullPage}) => {
+	` + locateJS + `
+	const root = document.documentElement;
+	const sx = window.scrollX, sy = window.scrollY;
+	const area = fullPage
+		? {left: 0, top: 0,
+		   right: Math.max(root.scrollWidth, document.body ? document.body.scrollWidth : 0),
+		   bottom: Math.max(root.scrollHeight, document.body ? document.body.scrollHeight : 0)}
+		: {left: sx, top: sy, right: sx + window.innerWidth, bottom: sy + window.innerHeight};
+
+	return selections.map((s, i) => {
+		const p = locate(s);
+		if (!p) {
+			return {notFound: true};
+		}
+
+		const l = Math.max(p.x, area.left), t = Math.max(p.y, area.top);
+		const r = Math.min(p.x + p.width, area.right), b = Math.min(p.y + p.height, area.bottom);
+		if (r <= l || b <= t) {
+			return {hidden: true};
+		}
+
+		const div = document.createElement('div');
+		div.id = 'selection-rect-' + i;
+		Object.assign(div.style, {
+			position: 'absolute',
+			left: l + 'px',
+			top: t + 'px',
+			width: (r - l) + 'px',
+			height: (b - t) + 'px',
+			border: style.borderWidth + 'px ' + style.borderStyle + ' ' + style.borderColor,
+			opacity: String(style.opacity),
+			boxSizing: 'border-box',
+			zIndex: '2147483647',
+			pointerEvents: 'none',
+		});
+		root.appendChild(div);
+		return {rect: {x: l, y: t, width: r - l, height: b - t}};
+	});
+}`
+
+// jsValue переводит v в map[string]interface{}, []interface{} и простые значения по его JSON-тегам.
+// Структуры и указатели на них playwright передает в page.Evaluate как undefined
+func jsValue(v interface{}) (interface{}, error) {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return nil, err
+	}
+	var value interface{}
+	if err := json.Unmarshal(data, &value); err != nil {
+		return nil, err
+	}
+	return value, nil
+}
+
+// selectionsArg аргумент drawSelectionsJS
+func selectionsArg(selections []SelectionArea, style *SelectionStyle, fullPage bool) (map[string]interface{}, error) {
+	if style == nil {
+		style = &defaultSelectionStyle
+	}
+	if selections == nil {
+		selections = []SelectionArea{}
+	}
+	jsSelections, err := jsValue(selections)
+	if err != nil {
+		return nil, err
+	}
+	jsStyle, err := jsValue(style)
+	if err != nil {
+		return nil, err
+	}
+	return map[string]interface{}{
+		"selections": jsSelections,
+		"style":      jsStyle,
+		"fullPage":   fullPage,
+	}, nil
+}
+
+// drawSelections рисует рамки выделений на странице. Возвращает нарисованные прямоугольники
+// в координатах страницы и номера выделений вне снимаемой области
+func drawSelections(page playwright.Page, selections []SelectionArea, style *SelectionStyle, fullPage bool) (drawn []interface{}, hidden []int, err error) {
+	arg, err := selectionsArg(selections, style, fullPage)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to draw selections: %w", err)
+	}
+	v, err := page.Evaluate(drawSelectionsJS, arg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to draw selections: %w", err)
+	}
+
+	results, _ := v.([]interface{})
+	for i, r := range results {
+		result, _ := r.(map[string]interface{})
+		if result["notFound"] == true {
+			return nil, nil, &SelectionError{Index: i, Selector: selections[i].Selector, Message: "element not found"}
+		}
+		if result["hidden"] == true {
+			hidden = append(hidden, i)
+			continue
+		}
+		drawn = append(drawn, result["rect"])
+	}
+	return drawn, hidden, nil
+}
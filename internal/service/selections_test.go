@@ -0,0 +1,96 @@
+package service
+
+import (
+	"fmt"
+	"testing"
+)
+
+// assertJSValue проверяет, что v состоит только из значений, которые playwright передает в page.Evaluate:
+// остальные, например структуры и указатели на них, приходят в скрипт как undefined
+func assertJSValue(t *testing.T, path string, v interface{}) {
+	t.Helper()
+	switch v := v.(type) {
+	case nil, string, bool, int, float64:
+	case map[string]interface{}:
+		for key, item := range v {
+			assertJSValue(t, path+"."+key, item)
+		}
+	case []interface{}:
+		for i, item := range v {
+			assertJSValue(t, fmt.Sprintf("%s[%d]", path, i), item)
+		}
+	default:
+		t.Errorf("%s: %T is passed to the page as undefined", path, v)
+	}
+}
+
+func TestSelectionsArg(t *testing.T) {
+	selections := []SelectionArea{
+		{X: 10, Y: 20, Width: 30, Height: 40},
+		{X: 5, Space: SelectionElement, Selector: "#card"},
+	}
+	arg, err := selectionsArg(selections, nil, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assertJSValue(t, "arg", arg)
+
+	items, ok := arg["selections"].([]interface{})
+	if !ok || len(items) != 2 {
+		t.Fatalf("selections = %#v, want 2 items", arg["selections"])
+	}
+	first, _ := items[0].(map[string]interface{})
+	if first["x"] != 10.0 || first["y"] != 20.0 || first["width"] != 30.0 || first["height"] != 40.0 {
+		t.Errorf("selection 0 = %#v", first)
+	}
+	second, _ := items[1].(map[string]interface{})
+	if second["space"] != SelectionElement || second["selector"] != "#card" {
+		t.Errorf("selection 1 = %#v", second)
+	}
+
+	style, _ := arg["style"].(map[string]interface{})
+	want := map[string]interface{}{"borderColor": "#FF0000", "borderWidth": 2.0, "borderStyle": "dashed", "opacity": 1.0}
+	for key, value := range want {
+		if style[key] != value {
+			t.Errorf("style.%s = %#v, want %#v", key, style[key], value)
+		}
+	}
+	if arg["fullPage"] != true {
+		t.Errorf("fullPage = %#v, want true", arg["fullPage"])
+	}
+
+	custom := &SelectionStyle{BorderColor: "blue", BorderWidth: 5, BorderStyle: "solid", Opacity: 0.5}
+	arg, err = selectionsArg(nil, custom, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if items, ok := arg["selections"].([]interface{}); !ok || len(items) != 0 {
+		t.Errorf("selections = %#v, want empty array", arg["selections"])
+	}
+	if style, _ := arg["style"].(map[string]interface{}); style["borderColor"] != "blue" || style["borderWidth"] != 5.0 {
+		t.Errorf("style = %#v", style)
+	}
+}
+
+func TestValidateSelections(t *testing.T) {
+	tests := []struct {
+		name       string
+		selections []SelectionArea
+		ok         bool
+	}{
+		{"page", []SelectionArea{{Width: 10, Height: 10}}, true},
+		{"viewport", []SelectionArea{{Width: 10, Height: 10, Space: SelectionViewport}}, true},
+		{"element size from element", []SelectionArea{{Space: SelectionElement, Selector: "#a"}}, true},
+		{"empty page area", []SelectionArea{{Width: 0, Height: 10}}, false},
+		{"element without selector", []SelectionArea{{Space: SelectionElement}}, false},
+		{"negative element size", []SelectionArea{{Space: SelectionElement, Selector: "#a", Width: -1}}, false},
+		{"unknown space", []SelectionArea{{Width: 10, Height: 10, Space: "screen"}}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if err := ValidateSelections(tt.selections); (err == nil) != tt.ok {
+				t.Errorf("ValidateSelections() = %v, want ok %v", err, tt.ok)
+			}
+		})
+	}
+}
@@ -1,16 +1,38 @@
 package service
 
+import "context"
+
 type Screenshot interface {
-	Make(html string, opts ScreenshotOptions) ([]byte, string, error)
+	Make(ctx context.Context, html string, opts ScreenshotOptions) (*Result, error)
+}
+
+// Размер окна браузера по умолчанию, как у playwright
+const (
+	DefaultViewportWidth  = 1280
+	DefaultViewportHeight = 720
+)
+
+// Result готовый скриншот и события страницы во время рендера
+type Result struct {
+	Image       []byte
+	ContentType string
+	Diagnostics Diagnostics
+	Artifacts   *Artifacts // HAR и трасса, если запрошены DebugArtifacts
+}
+
+// Differ сравнивает два изображения попиксельно
+type Differ interface {
+	Compare(baseline, actual []byte, opts DiffOptions) (*DiffResult, error)
 }
 
+// SelectionArea выделенная область. Части за пределами снимка обрезаются
 type SelectionArea struct {
-	X       int `json:"x"`                 // Координата X начальной точки
-	Y       int `json:"y"`                 // Координата Y начальной точки
-	Width   int `json:"width"`             // Ширина выделенной области
-	Height  int `json:"height"`            // Высота выделенной области
-	ScrollX int `json:"scrollx,omitempty"` // Горизонтальная прокрутка
-	ScrollY int `json:"scrolly,omitempty"` // Вертикальная прокрутка
+	X        int    `json:"x"`                  // Координата X начальной точки
+	Y        int    `json:"y"`                  // Координата Y начальной точки
+	Width    int    `json:"width"`              // Ширина выделенной области, для element 0 - ширина элемента
+	Height   int    `json:"height"`             // Высота выделенной области, для element 0 - высота элемента
+	Space    string `json:"space,omitempty"`    // Система координат: page, viewport или element
+	Selector string `json:"selector,omitempty"` // CSS-селектор элемента для element, X и Y - смещение от его угла
 }
 
 // SelectionStyle стиль выделения
@@ -32,19 +54,94 @@ type ScreenshotOptions struct {
 		Width  int `json:"width"`
 		Height int `json:"height"`
 	} `json:"viewport"`
-	Timeout        float64 `json:"timeout"`
-	Selections     []SelectionArea
-	SelectionStyle *SelectionStyle
-	ScrollX        int `json:"scrollx"`
-	ScrollY        int `json:"scrolly"`
+	Timeout        float64         `json:"timeout"`
+	Selections     []SelectionArea `json:"selections"`
+	SelectionStyle *SelectionStyle `json:"selection_style"`
+	ScrollX        int             `json:"scrollx"`
+	ScrollY        int             `json:"scrolly"`
+	URL            string          `json:"url"`     // Адрес страницы, используется вместо html
+	Actions        []Action        `json:"actions"` // Действия со страницей перед скриншотом
+
+	Annotations      []Annotation     `json:"annotations"`       // Стрелки, выноски и номера шагов поверх страницы
+	AnnotationStyle  *AnnotationStyle `json:"annotation_style"`  // Стиль аннотаций по умолчанию
+	Spotlight        bool             `json:"spotlight"`         // Затемнить все, кроме выделений
+	SpotlightOpacity float64          `json:"spotlight_opacity"` // Непрозрачность затемнения (0.0 - 1.0)
+	Watermark        *Watermark       `json:"watermark"`         // Водяной знак поверх готового изображения
+
+	Duration         float64 `json:"duration"`   // Длительность записи gif и webm (мс)
+	FrameRate        int     `json:"frame_rate"` // Частота кадров gif, webm записывается с частотой playwright
+	MaxAnimationSize int     `json:"-"`          // Максимальный размер gif и webm (байт), задается сервером
+
+	// Селектор элемента с собственной прокруткой: элемент снимается по шагам и склеивается в одно изображение
+	ScrollContainer string `json:"scroll_container"`
+
+	FailOnPageError bool    `json:"fail_on_page_error"` // Прервать рендер при необработанном исключении на странице
+	DebugArtifacts  bool    `json:"debug_artifacts"`    // Записать HAR и трассу playwright
+	Bundle          *Bundle `json:"-"`                  // Страница с ресурсами, используется вместо html
+	DocumentMode    string  `json:"document_mode"`      // Загрузка html: file (file://) или http (по http-адресу)
+	BaseURL         string  `json:"base_url"`           // Адрес, по которому отдается html, относительные ссылки ведут на этот сайт
+	DocumentOrigin  string  `json:"-"`                  // Синтетический origin документа в режиме http, задается сервером
+	MaxHeight       int     `json:"-"`                  // Максимальная высота страницы при FullPage, задается сервером
+	MaxScrollSteps  int     `json:"-"`                  // Максимальное число шагов прокрутки ScrollContainer, задается сервером
+
+	LaunchPreset string        `json:"launch_preset"` // Имя пресета запуска браузера из конфигурации сервера
+	Launch       *LaunchPreset `json:"-"`             // Настройки выбранного пресета, задаются сервером
+	Proxy        *Proxy        `json:"proxy"`         // Прокси запроса, ключа или сервера, nil - без прокси
+
+	Emulation   // Цветовая схема, язык, часовой пояс и другие настройки пользователя
+	Credentials // Заголовки, куки, basic-аутентификация и состояние хранилища
+}
+
+// OutputType формат результата: png, jpeg, gif или webm
+func (o ScreenshotOptions) OutputType() string {
+	switch o.Type {
+	case "jpeg", "jpg":
+		return "jpeg"
+	case TypeGIF, TypeWebM:
+		return o.Type
+	}
+	return "png"
+}
+
+// DiffOptions параметры попиксельного сравнения
+type DiffOptions struct {
+	Threshold          float64         `json:"threshold"`           // Чувствительность к разнице цвета (0.0 - 1.0)
+	IgnoreAntialiasing bool            `json:"ignore_antialiasing"` // Не считать отличием пиксели сглаживания
+	AATolerance        int             `json:"aa_tolerance"`        // Допустимое число одинаковых соседей у пикселя сглаживания (0 - 8)
+	SelectionStyle     *SelectionStyle `json:"selection_style"`     // Стиль обводки изменённых областей
+	MaxWidth           int             `json:"-"`                   // Максимальная ширина изображений (px), задается сервером
+	MaxHeight          int             `json:"-"`                   // Максимальная высота изображений (px), задается сервером
+}
+
+// DiffRegion прямоугольная область с отличиями
+type DiffRegion struct {
+	X      int `json:"x"`
+	Y      int `json:"y"`
+	Width  int `json:"width"`
+	Height int `json:"height"`
+}
+
+// DiffResult результат сравнения изображений
+type DiffResult struct {
+	Width              int          `json:"width"`
+	Height             int          `json:"height"`
+	TotalPixels        int          `json:"total_pixels"`
+	MismatchedPixels   int          `json:"mismatched_pixels"`
+	MismatchPercentage float64      `json:"mismatch_percentage"`
+	Regions            []DiffRegion `json:"regions"`
+	Image              []byte       `json:"-"` // Изображение с подсвеченными отличиями (PNG)
 }
 
 type Service struct {
 	Screenshot Screenshot
+	Diff       Differ
+	Templates  *Templates // nil, если директория шаблонов не задана
 }
 
-func NewService(s Screenshot) *Service {
+func NewService(s Screenshot, d Differ, t *Templates) *Service {
 	return &Service{
 		Screenshot: s,
+		Diff:       d,
+		Templates:  t,
 	}
 }
@@ -0,0 +1,212 @@
+package service
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"image"
+	"image/draw"
+	"image/png"
+
+	"github.com/playwright-community/playwright-go"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// lazyLoadTimeout сколько ждать догрузки содержимого после каждой прокрутки (мс)
+const lazyLoadTimeout = 2000.0
+
+// stitchStepBudget время на один шаг склейки с учетом догрузки и снимка (мс)
+const stitchStepBudget = lazyLoadTimeout + 1000
+
+// defaultStitchSteps число шагов для оценки времени склейки, если сервер их не ограничивает
+const defaultStitchSteps = 50
+
+// stitchDuration наибольшее время склейки ScrollContainer (мс)
+func stitchDuration(opts ScreenshotOptions) float64 {
+	if opts.ScrollContainer == "" {
+		return 0
+	}
+	steps := opts.MaxScrollSteps
+	if steps <= 0 {
+		steps = defaultStitchSteps
+	}
+	return float64(steps) * stitchStepBudget
+}
+
+// containerMetrics размеры прокручиваемого элемента в CSS-пикселях
+type containerMetrics struct {
+	ScrollTop    int
+	ScrollHeight int
+	ClientTop    int // Толщина верхней рамки
+	ClientHeight int // Видимая высота содержимого без рамок и полосы прокрутки
+}
+
+const containerMetricsJS = `el => ({
+	scrollTop: Math.round(el.scrollTop),
+	scrollHeight: el.scrollHeight,
+	clientTop: el.clientTop,
+	clientHeight: el.clientHeight,
+})`
+
+// captureScrollContainer прокручивает элемент ScrollContainer по одному экрану,
+// снимает каждый шаг и склеивает снимки в одно изображение. Съемка останавливается
+// в конце содержимого, после MaxScrollSteps шагов или на высоте MaxHeight.
+// Выделения рисуются поверх склеенного изображения в его координатах
+func captureScrollContainer(ctx context.Context, page playwright.Page, opts ScreenshotOptions) ([]byte, error) {
+	container := page.Locator(opts.ScrollContainer)
+	if err := container.WaitFor(playwright.LocatorWaitForOptions{Timeout: playwright.Float(opts.Timeout)}); err != nil {
+		return nil, fmt.Errorf("scroll container %q not found: %w", opts.ScrollContainer, err)
+	}
+
+	if _, err := container.Evaluate("el => { el.scrollTop = 0 }", nil); err != nil {
+		return nil, fmt.Errorf("failed to scroll container: %w", err)
+	}
+	m, err := readContainerMetrics(container)
+	if err != nil {
+		return nil, err
+	}
+
+	first, err := captureContainer(container, opts)
+	if err != nil {
+		return nil, err
+	}
+	contentBottom := min(m.ClientTop+m.ClientHeight, first.Bounds().Dy())
+	// Нижняя рамка и полоса прокрутки входят в итоговую высоту, поэтому вычитаются из MaxHeight
+	maxHeight := opts.MaxHeight - (first.Bounds().Dy() - contentBottom)
+
+	// Склеенное изображение: верх первого снимка с рамкой, затем новые строки каждого шага
+	slices := []image.Image{first.SubImage(image.Rect(0, 0, first.Bounds().Dx(), contentBottom))}
+	height := contentBottom
+	last := first
+	steps := 1
+
+	for {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		if m.ScrollTop+m.ClientHeight >= m.ScrollHeight ||
+			(opts.MaxScrollSteps > 0 && steps >= opts.MaxScrollSteps) ||
+			(opts.MaxHeight > 0 && height >= maxHeight) {
+			break
+		}
+
+		prev := m.ScrollTop
+		if _, err := container.Evaluate("el => { el.scrollTop += el.clientHeight }", nil); err != nil {
+			return nil, fmt.Errorf("failed to scroll container: %w", err)
+		}
+		// Даем странице догрузить содержимое; таймаут ожидания не ошибка
+		_ = page.WaitForLoadState(playwright.PageWaitForLoadStateOptions{
+			State:   playwright.LoadStateNetworkidle,
+			Timeout: playwright.Float(lazyLoadTimeout),
+		})
+		page.WaitForTimeout(100)
+
+		if m, err = readContainerMetrics(container); err != nil {
+			return nil, err
+		}
+		delta := m.ScrollTop - prev
+		if delta <= 0 {
+			break
+		}
+
+		if last, err = captureContainer(container, opts); err != nil {
+			return nil, err
+		}
+		steps++
+		delta = min(delta, contentBottom-m.ClientTop)
+		if opts.MaxHeight > 0 {
+			delta = min(delta, maxHeight-height)
+		}
+		slices = append(slices, last.SubImage(image.Rect(0, contentBottom-delta, last.Bounds().Dx(), contentBottom)))
+		height += delta
+	}
+
+	// Нижняя рамка и горизонтальная полоса прокрутки из последнего снимка
+	slices = append(slices, last.SubImage(image.Rect(0, contentBottom, last.Bounds().Dx(), last.Bounds().Dy())))
+
+	trace.SpanFromContext(ctx).SetAttributes(
+		attribute.Int("screenshot.scroll_steps", steps),
+		attribute.Int("screenshot.stitched_height", height),
+	)
+	return encodeStitched(slices, opts)
+}
+
+// readContainerMetrics читает текущие размеры и прокрутку элемента
+func readContainerMetrics(container playwright.Locator) (containerMetrics, error) {
+	v, err := container.Evaluate(containerMetricsJS, nil)
+	if err != nil {
+		return containerMetrics{}, fmt.Errorf("failed to measure scroll container: %w", err)
+	}
+	values, _ := v.(map[string]interface{})
+	return containerMetrics{
+		ScrollTop:    toInt(values["scrollTop"]),
+		ScrollHeight: toInt(values["scrollHeight"]),
+		ClientTop:    toInt(values["clientTop"]),
+		ClientHeight: toInt(values["clientHeight"]),
+	}, nil
+}
+
+// captureContainer снимок видимой части элемента, один пиксель на CSS-пиксель
+func captureContainer(container playwright.Locator, opts ScreenshotOptions) (*image.RGBA, error) {
+	data, err := container.Screenshot(playwright.LocatorScreenshotOptions{
+		Type:           playwright.ScreenshotTypePng,
+		Scale:          playwright.ScreenshotScaleCss,
+		Animations:     playwright.ScreenshotAnimationsDisabled,
+		OmitBackground: playwright.Bool(opts.OmitBackground),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to capture scroll container: %w", err)
+	}
+	img, err := png.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode scroll container capture: %w", err)
+	}
+	return toRGBA(img), nil
+}
+
+// encodeStitched склеивает части по вертикали, рисует выделения и кодирует в формат запроса
+func encodeStitched(slices []image.Image, opts ScreenshotOptions) ([]byte, error) {
+	width, height := 0, 0
+	for _, s := range slices {
+		width = max(width, s.Bounds().Dx())
+		height += s.Bounds().Dy()
+	}
+	// Склеенное изображение ограничено той же высотой, что и полноразмерный скриншот
+	if opts.MaxHeight > 0 && height > opts.MaxHeight {
+		return nil, &LimitError{Limit: "max_full_page_height", Value: height, Max: opts.MaxHeight}
+	}
+
+	out := image.NewRGBA(image.Rect(0, 0, width, height))
+	y := 0
+	for _, s := range slices {
+		r := image.Rect(0, y, s.Bounds().Dx(), y+s.Bounds().Dy())
+		draw.Draw(out, r, s, s.Bounds().Min, draw.Src)
+		y += s.Bounds().Dy()
+	}
+
+	style := opts.SelectionStyle
+	if style == nil {
+		style = &defaultSelectionStyle
+	}
+	var rects []image.Rectangle
+	for i, sel := range opts.Selections {
+		if sel.Space == SelectionElement || sel.Space == SelectionViewport {
+			return nil, &SelectionError{Index: i, Message: "only page space is supported with scroll_container"}
+		}
+		rect := image.Rect(sel.X, sel.Y, sel.X+sel.Width, sel.Y+sel.Height).Intersect(out.Bounds())
+		if !rect.Empty() {
+			rects = append(rects, rect)
+		}
+	}
+	if opts.Spotlight {
+		dimOutside(out, rects, opts.spotlightOpacity())
+	}
+	for _, rect := range rects {
+		if err := drawSelection(out, rect, style); err != nil {
+			return nil, fmt.Errorf("failed to draw selection: %w", err)
+		}
+	}
+
+	return encodeImage(out, opts)
+}
@@ -0,0 +1,241 @@
+package service
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"html/template"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"syscall"
+	texttemplate "text/template"
+	"time"
+)
+
+// templatesWatchInterval период проверки изменений в директории шаблонов
+const templatesWatchInterval = 5 * time.Second
+
+// Templates реестр html-шаблонов (html/template) из директории.
+// Каждый файл *.html - шаблон с именем файла без расширения, файлы _*.html - общие части,
+// доступные всем шаблонам через {{template "..."}}
+type Templates struct {
+	dir     string
+	mu      sync.Mutex
+	current atomic.Pointer[map[string]*Template]
+	modTime time.Time // последнее изменение загруженных файлов
+	files   int       // число загруженных файлов
+}
+
+// Template шаблон страницы
+type Template struct {
+	Name     string    `json:"name"`
+	Hash     string    `json:"hash"` // sha256 исходников шаблона и общих частей
+	Modified time.Time `json:"modified"`
+
+	tmpl *template.Template
+}
+
+// TemplateDataError данные не подходят шаблону
+type TemplateDataError struct {
+	Field   string `json:"field"`   // Поле данных, например user.name
+	Line    int    `json:"line"`    // Строка шаблона
+	Column  int    `json:"column"`  // Столбец шаблона
+	Message string `json:"message"` // Описание ошибки
+}
+
+func (e *TemplateDataError) Error() string {
+	return fmt.Sprintf("invalid template data at %q: %s", e.Field, e.Message)
+}
+
+// NewTemplates загружает шаблоны из директории
+func NewTemplates(dir string) (*Templates, error) {
+	t := &Templates{dir: dir}
+	if err := t.Reload(); err != nil {
+		return nil, err
+	}
+	return t, nil
+}
+
+// Reload перечитывает директорию. При ошибке в любом шаблоне остаются текущие
+func (t *Templates) Reload() error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	files, err := filepath.Glob(filepath.Join(t.dir, "*.html"))
+	if err != nil {
+		return fmt.Errorf("failed to list templates: %w", err)
+	}
+
+	var pages, partials []string
+	for _, file := range files {
+		if strings.HasPrefix(filepath.Base(file), "_") {
+			partials = append(partials, file)
+		} else {
+			pages = append(pages, file)
+		}
+	}
+
+	// Общие части входят в хеш каждого шаблона
+	shared := sha256.New()
+	var modTime time.Time
+	for _, file := range partials {
+		data, info, err := readTemplateFile(file)
+		if err != nil {
+			return err
+		}
+		shared.Write(data)
+		modTime = latest(modTime, info.ModTime())
+	}
+
+	templates := make(map[string]*Template, len(pages))
+	for _, file := range pages {
+		data, info, err := readTemplateFile(file)
+		if err != nil {
+			return err
+		}
+		name := strings.TrimSuffix(filepath.Base(file), ".html")
+
+		tmpl := template.New(filepath.Base(file)).Option("missingkey=error")
+		if _, err := tmpl.Parse(string(data)); err != nil {
+			return fmt.Errorf("invalid template %s: %w", name, err)
+		}
+		if len(partials) > 0 {
+			if _, err := tmpl.ParseFiles(partials...); err != nil {
+				return fmt.Errorf("invalid template %s: %w", name, err)
+			}
+		}
+
+		hash := sha256.New()
+		hash.Write(shared.Sum(nil))
+		hash.Write(data)
+		templates[name] = &Template{
+			Name:     name,
+			Hash:     hex.EncodeToString(hash.Sum(nil)),
+			Modified: latest(modTime, info.ModTime()),
+			tmpl:     tmpl,
+		}
+		modTime = latest(modTime, info.ModTime())
+	}
+
+	t.current.Store(&templates)
+	t.modTime = modTime
+	t.files = len(files)
+	return nil
+}
+
+// Get шаблон по имени
+func (t *Templates) Get(name string) (*Template, bool) {
+	tpl, ok := (*t.current.Load())[name]
+	return tpl, ok
+}
+
+// List шаблоны, отсортированные по имени
+func (t *Templates) List() []*Template {
+	templates := *t.current.Load()
+	list := make([]*Template, 0, len(templates))
+	for _, tpl := range templates {
+		list = append(list, tpl)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Watch перечитывает шаблоны по сигналу SIGHUP и при изменении файлов.
+// Результат каждой попытки передаётся в report
+func (t *Templates) Watch(ctx context.Context, report func(err error)) {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+
+	ticker := time.NewTicker(templatesWatchInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-hup:
+		case <-ticker.C:
+			if !t.changed() {
+				continue
+			}
+		}
+		report(t.Reload())
+	}
+}
+
+// changed изменились ли файлы шаблонов с последней загрузки
+func (t *Templates) changed() bool {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	files, _ := filepath.Glob(filepath.Join(t.dir, "*.html"))
+	var modTime time.Time
+	for _, file := range files {
+		if info, err := os.Stat(file); err == nil {
+			modTime = latest(modTime, info.ModTime())
+		}
+	}
+	return !modTime.Equal(t.modTime) || len(files) != t.files
+}
+
+// Render подставляет данные в шаблон. Если данных не хватает или они другого типа,
+// возвращает *TemplateDataError с путем к полю
+func (tpl *Template) Render(data any) (string, error) {
+	var buf strings.Builder
+	if err := tpl.tmpl.Execute(&buf, data); err != nil {
+		var execErr texttemplate.ExecError
+		if errors.As(err, &execErr) {
+			return "", templateDataError(execErr)
+		}
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// execErrorPattern разбирает ошибку text/template:
+// template: card.html:3:12: executing "card.html" at <.user.name>: map has no entry for key "name"
+var execErrorPattern = regexp.MustCompile(`^template: [^:]+:(\d+):(\d+): executing "[^"]*" at <([^>]*)>: (.*)$`)
+
+func templateDataError(err texttemplate.ExecError) *TemplateDataError {
+	m := execErrorPattern.FindStringSubmatch(err.Error())
+	if m == nil {
+		return &TemplateDataError{Message: err.Err.Error()}
+	}
+	line, _ := strconv.Atoi(m[1])
+	column, _ := strconv.Atoi(m[2])
+	return &TemplateDataError{
+		Field:   strings.TrimPrefix(m[3], "."),
+		Line:    line,
+		Column:  column,
+		Message: m[4],
+	}
+}
+
+func readTemplateFile(file string) ([]byte, os.FileInfo, error) {
+	info, err := os.Stat(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read template %s: %w", file, err)
+	}
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read template %s: %w", file, err)
+	}
+	return data, info, nil
+}
+
+func latest(a, b time.Time) time.Time {
+	if b.After(a) {
+		return b
+	}
+	return a
+}
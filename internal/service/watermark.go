@@ -0,0 +1,220 @@
+package service
+
+import (
+	"bytes"
+	"fmt"
+	"image"
+	"image/color"
+	"image/draw"
+	_ "image/jpeg"
+	"image/png"
+	"screenshoter/config"
+
+	"golang.org/x/image/font"
+	"golang.org/x/image/font/gofont/gobold"
+	"golang.org/x/image/font/opentype"
+	"golang.org/x/image/math/fixed"
+)
+
+// Положение водяного знака
+const (
+	WatermarkTopLeft     = "top-left"
+	WatermarkTopRight    = "top-right"
+	WatermarkBottomLeft  = "bottom-left"
+	WatermarkBottomRight = "bottom-right"
+	WatermarkCenter      = "center"
+)
+
+// Значения водяного знака по умолчанию
+const (
+	DefaultWatermarkOpacity  = 0.5
+	DefaultWatermarkColor    = "white"
+	DefaultWatermarkFontSize = 24
+)
+
+// Watermark водяной знак, накладываемый на готовое изображение: текст или PNG
+type Watermark struct {
+	Text     string  `json:"text,omitempty"`      // Текст знака
+	Image    []byte  `json:"-"`                   // PNG вместо текста
+	Position string  `json:"position,omitempty"`  // top-left, top-right, bottom-left, bottom-right или center
+	Opacity  float64 `json:"opacity,omitempty"`   // Непрозрачность (0.0 - 1.0)
+	Tile     bool    `json:"tile,omitempty"`      // Повторять знак по всему изображению
+	Margin   int     `json:"margin,omitempty"`    // Отступ от края, при Tile - промежуток между знаками (px)
+	Color    string  `json:"color,omitempty"`     // Цвет текста (CSS-формат)
+	FontSize int     `json:"font_size,omitempty"` // Размер шрифта текста (px)
+}
+
+// Validate проверяет параметры водяного знака
+func (w *Watermark) Validate() error {
+	if w.Text == "" && len(w.Image) == 0 {
+		return fmt.Errorf("watermark text or image is required")
+	}
+	if w.Text != "" && len(w.Image) > 0 {
+		return fmt.Errorf("watermark text and image are mutually exclusive")
+	}
+	switch w.Position {
+	case "", WatermarkTopLeft, WatermarkTopRight, WatermarkBottomLeft, WatermarkBottomRight, WatermarkCenter:
+	default:
+		return fmt.Errorf("watermark position must be top-left, top-right, bottom-left, bottom-right or center")
+	}
+	if w.Opacity < 0 || w.Opacity > 1 {
+		return fmt.Errorf("watermark opacity must be between 0 and 1")
+	}
+	if w.Margin < 0 || w.FontSize < 0 {
+		return fmt.Errorf("watermark margin and font size must not be negative")
+	}
+	if w.Color != "" {
+		if _, err := config.ParseColor(w.Color); err != nil {
+			return fmt.Errorf("watermark color: %w", err)
+		}
+	}
+	if len(w.Image) > 0 {
+		if _, err := png.DecodeConfig(bytes.NewReader(w.Image)); err != nil {
+			return fmt.Errorf("watermark image must be a PNG: %w", err)
+		}
+	}
+	return nil
+}
+
+// Override знак w с заданными полями o; текст и изображение o заменяют друг друга
+func (w Watermark) Override(o Watermark) *Watermark {
+	if o.Text != "" || len(o.Image) > 0 {
+		w.Text, w.Image = o.Text, o.Image
+	}
+	if o.Position != "" {
+		w.Position = o.Position
+	}
+	if o.Opacity > 0 {
+		w.Opacity = o.Opacity
+	}
+	if o.Tile {
+		w.Tile = true
+	}
+	if o.Margin > 0 {
+		w.Margin = o.Margin
+	}
+	if o.Color != "" {
+		w.Color = o.Color
+	}
+	if o.FontSize > 0 {
+		w.FontSize = o.FontSize
+	}
+	return &w
+}
+
+// applyWatermark накладывает знак на изображение и кодирует его в формат opts
+func applyWatermark(data []byte, opts ScreenshotOptions) ([]byte, error) {
+	src, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return nil, fmt.Errorf("failed to decode screenshot: %w", err)
+	}
+	out := image.NewRGBA(src.Bounds())
+	draw.Draw(out, out.Bounds(), src, src.Bounds().Min, draw.Src)
+
+	if err := drawWatermark(out, opts.Watermark); err != nil {
+		return nil, err
+	}
+	return encodeImage(out, opts)
+}
+
+// drawWatermark рисует знак w на img
+func drawWatermark(img *image.RGBA, w *Watermark) error {
+	mark, err := w.render()
+	if err != nil {
+		return err
+	}
+	opacity := w.Opacity
+	if opacity == 0 {
+		opacity = DefaultWatermarkOpacity
+	}
+	mask := image.NewUniform(color.Alpha{A: uint8(clamp(opacity, 0, 1) * 255)})
+
+	b, size := img.Bounds(), mark.Bounds().Size()
+	put := func(p image.Point) {
+		draw.DrawMask(img, image.Rectangle{Min: p, Max: p.Add(size)}, mark, mark.Bounds().Min, mask, image.Point{}, draw.Over)
+	}
+
+	if w.Tile {
+		// Шахматный порядок, чтобы знак не складывался в ровные столбцы
+		stepX, stepY := size.X+max(w.Margin, size.Y), size.Y+max(w.Margin, size.Y)
+		for row, y := 0, b.Min.Y+w.Margin; y < b.Max.Y; row, y = row+1, y+stepY {
+			for x := b.Min.X + w.Margin - (row%2)*stepX/2; x < b.Max.X; x += stepX {
+				put(image.Pt(x, y))
+			}
+		}
+		return nil
+	}
+
+	var p image.Point
+	switch w.Position {
+	case WatermarkTopLeft:
+		p = image.Pt(b.Min.X+w.Margin, b.Min.Y+w.Margin)
+	case WatermarkTopRight:
+		p = image.Pt(b.Max.X-w.Margin-size.X, b.Min.Y+w.Margin)
+	case WatermarkBottomLeft:
+		p = image.Pt(b.Min.X+w.Margin, b.Max.Y-w.Margin-size.Y)
+	case WatermarkCenter:
+		p = image.Pt(b.Min.X+(b.Dx()-size.X)/2, b.Min.Y+(b.Dy()-size.Y)/2)
+	default:
+		p = image.Pt(b.Max.X-w.Margin-size.X, b.Max.Y-w.Margin-size.Y)
+	}
+	put(p)
+	return nil
+}
+
+// render изображение знака: PNG как есть или текст с тенью для читаемости на светлом и темном фоне
+func (w *Watermark) render() (image.Image, error) {
+	if len(w.Image) > 0 {
+		img, err := png.Decode(bytes.NewReader(w.Image))
+		if err != nil {
+			return nil, fmt.Errorf("failed to decode watermark image: %w", err)
+		}
+		return img, nil
+	}
+
+	colorName := w.Color
+	if colorName == "" {
+		colorName = DefaultWatermarkColor
+	}
+	c, err := config.ParseColor(colorName)
+	if err != nil {
+		return nil, err
+	}
+	size := w.FontSize
+	if size == 0 {
+		size = DefaultWatermarkFontSize
+	}
+	face, err := watermarkFace(size)
+	if err != nil {
+		return nil, err
+	}
+	defer face.Close()
+
+	shadow := max(1, size/16)
+	metrics := face.Metrics()
+	width := font.MeasureString(face, w.Text).Ceil() + shadow
+	height := (metrics.Ascent + metrics.Descent).Ceil() + shadow
+	img := image.NewRGBA(image.Rect(0, 0, width, height))
+
+	d := &font.Drawer{Dst: img, Face: face}
+	d.Src = image.NewUniform(color.RGBA{A: 160})
+	d.Dot = fixed.Point26_6{X: fixed.I(shadow), Y: metrics.Ascent + fixed.I(shadow)}
+	d.DrawString(w.Text)
+	d.Src = image.NewUniform(c)
+	d.Dot = fixed.Point26_6{Y: metrics.Ascent}
+	d.DrawString(w.Text)
+	return img, nil
+}
+
+// watermarkFace шрифт текста водяного знака
+func watermarkFace(size int) (font.Face, error) {
+	f, err := opentype.Parse(gobold.TTF)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse watermark font: %w", err)
+	}
+	face, err := opentype.NewFace(f, &opentype.FaceOptions{Size: float64(size), DPI: 72, Hinting: font.HintingFull})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create watermark font: %w", err)
+	}
+	return face, nil
+}
@@ -0,0 +1,91 @@
+package workerpool
+
+import (
+	"context"
+	"screenshoter/internal/metrics"
+	"sync"
+)
+
+// Pool ограничивает число одновременно создаваемых скриншотов
+type Pool struct {
+	mu     sync.Mutex
+	size   int
+	active int
+	free   chan struct{} // Закрывается, когда появляется свободный слот
+}
+
+func New(size int) *Pool {
+	return &Pool{size: size}
+}
+
+// Acquire ждет свободный слот, пока не отменен ctx
+func (p *Pool) Acquire(ctx context.Context) (func(), error) {
+	for {
+		release, free := p.tryAcquire()
+		if release != nil {
+			return release, nil
+		}
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-free:
+		}
+	}
+}
+
+// tryAcquire занимает слот, если пул заполнен - возвращает канал, который закроется при освобождении слота
+func (p *Pool) tryAcquire() (func(), <-chan struct{}) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if p.active >= p.size {
+		if p.free == nil {
+			p.free = make(chan struct{})
+		}
+		return nil, p.free
+	}
+	p.active++
+	metrics.ActiveWorkers.Inc() // Увеличиваем счетчик активных воркеров
+
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			p.mu.Lock()
+			p.active--
+			p.notify()
+			p.mu.Unlock()
+			metrics.ActiveWorkers.Dec() // Уменьшаем счетчик при освобождении
+		})
+	}, nil
+}
+
+// notify будит ожидающих слот, вызывается под mu
+func (p *Pool) notify() {
+	if p.free != nil {
+		close(p.free)
+		p.free = nil
+	}
+}
+
+// Resize меняет размер пула. При уменьшении уже занятые слоты
+// дорабатывают, новые не выдаются, пока занято не меньше size
+func (p *Pool) Resize(size int) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.size = size
+	p.notify()
+}
+
+// Active количество занятых слотов
+func (p *Pool) Active() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.active
+}
+
+// Size размер пула
+func (p *Pool) Size() int {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.size
+}
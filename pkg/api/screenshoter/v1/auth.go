@@ -0,0 +1,24 @@
+package screenshoterv1
+
+import "context"
+
+// TokenAuth передаёт токен доступа в заголовке authorization каждого вызова:
+//
+//	conn, err := grpc.NewClient(addr,
+//		grpc.WithTransportCredentials(insecure.NewCredentials()),
+//		grpc.WithPerRPCCredentials(screenshoterv1.TokenAuth{Token: "secret"}),
+//	)
+//	client := screenshoterv1.NewScreenshoterClient(conn)
+type TokenAuth struct {
+	Token string
+	// Secure запрещает отправку токена по незащищённому соединению
+	Secure bool
+}
+
+func (t TokenAuth) GetRequestMetadata(_ context.Context, _ ...string) (map[string]string, error) {
+	return map[string]string{"authorization": "Bearer " + t.Token}, nil
+}
+
+func (t TokenAuth) RequireTransportSecurity() bool {
+	return t.Secure
+}
@@ -0,0 +1,912 @@
+// Code generated by protoc-gen-go. DO NOT EDIT.
+// versions:
+// 	protoc-gen-go v1.36.11
+// 	protoc        (unknown)
+// source: screenshoter/v1/screenshoter.proto
+
+package screenshoterv1
+
+import (
+	protoreflect "google.golang.org/protobuf/reflect/protoreflect"
+	protoimpl "google.golang.org/protobuf/runtime/protoimpl"
+	timestamppb "google.golang.org/protobuf/types/known/timestamppb"
+	reflect "reflect"
+	sync "sync"
+	unsafe "unsafe"
+)
+
+const (
+	// Verify that this generated code is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(20 - protoimpl.MinVersion)
+	// Verify that runtime/protoimpl is sufficiently up-to-date.
+	_ = protoimpl.EnforceVersion(protoimpl.MaxVersion - 20)
+)
+
+type Browser int32
+
+const (
+	Browser_BROWSER_UNSPECIFIED Browser = 0 // chromium
+	Browser_BROWSER_CHROMIUM    Browser = 1
+	Browser_BROWSER_FIREFOX     Browser = 2
+	Browser_BROWSER_WEBKIT      Browser = 3
+)
+
+// Enum value maps for Browser.
+var (
+	Browser_name = map[int32]string{
+		0: "BROWSER_UNSPECIFIED",
+		1: "BROWSER_CHROMIUM",
+		2: "BROWSER_FIREFOX",
+		3: "BROWSER_WEBKIT",
+	}
+	Browser_value = map[string]int32{
+		"BROWSER_UNSPECIFIED": 0,
+		"BROWSER_CHROMIUM":    1,
+		"BROWSER_FIREFOX":     2,
+		"BROWSER_WEBKIT":      3,
+	}
+)
+
+func (x Browser) Enum() *Browser {
+	p := new(Browser)
+	*p = x
+	return p
+}
+
+func (x Browser) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (Browser) Descriptor() protoreflect.EnumDescriptor {
+	return file_screenshoter_v1_screenshoter_proto_enumTypes[0].Descriptor()
+}
+
+func (Browser) Type() protoreflect.EnumType {
+	return &file_screenshoter_v1_screenshoter_proto_enumTypes[0]
+}
+
+func (x Browser) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use Browser.Descriptor instead.
+func (Browser) EnumDescriptor() ([]byte, []int) {
+	return file_screenshoter_v1_screenshoter_proto_rawDescGZIP(), []int{0}
+}
+
+type JobStatus int32
+
+const (
+	JobStatus_JOB_STATUS_UNSPECIFIED JobStatus = 0
+	JobStatus_JOB_STATUS_RUNNING     JobStatus = 1
+	JobStatus_JOB_STATUS_DONE        JobStatus = 2
+	JobStatus_JOB_STATUS_FAILED      JobStatus = 3
+)
+
+// Enum value maps for JobStatus.
+var (
+	JobStatus_name = map[int32]string{
+		0: "JOB_STATUS_UNSPECIFIED",
+		1: "JOB_STATUS_RUNNING",
+		2: "JOB_STATUS_DONE",
+		3: "JOB_STATUS_FAILED",
+	}
+	JobStatus_value = map[string]int32{
+		"JOB_STATUS_UNSPECIFIED": 0,
+		"JOB_STATUS_RUNNING":     1,
+		"JOB_STATUS_DONE":        2,
+		"JOB_STATUS_FAILED":      3,
+	}
+)
+
+func (x JobStatus) Enum() *JobStatus {
+	p := new(JobStatus)
+	*p = x
+	return p
+}
+
+func (x JobStatus) String() string {
+	return protoimpl.X.EnumStringOf(x.Descriptor(), protoreflect.EnumNumber(x))
+}
+
+func (JobStatus) Descriptor() protoreflect.EnumDescriptor {
+	return file_screenshoter_v1_screenshoter_proto_enumTypes[1].Descriptor()
+}
+
+func (JobStatus) Type() protoreflect.EnumType {
+	return &file_screenshoter_v1_screenshoter_proto_enumTypes[1]
+}
+
+func (x JobStatus) Number() protoreflect.EnumNumber {
+	return protoreflect.EnumNumber(x)
+}
+
+// Deprecated: Use JobStatus.Descriptor instead.
+func (JobStatus) EnumDescriptor() ([]byte, []int) {
+	return file_screenshoter_v1_screenshoter_proto_rawDescGZIP(), []int{1}
+}
+
+type Viewport struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Width         int32                  `protobuf:"varint,1,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32                  `protobuf:"varint,2,opt,name=height,proto3" json:"height,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Viewport) Reset() {
+	*x = Viewport{}
+	mi := &file_screenshoter_v1_screenshoter_proto_msgTypes[0]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Viewport) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Viewport) ProtoMessage() {}
+
+func (x *Viewport) ProtoReflect() protoreflect.Message {
+	mi := &file_screenshoter_v1_screenshoter_proto_msgTypes[0]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Viewport.ProtoReflect.Descriptor instead.
+func (*Viewport) Descriptor() ([]byte, []int) {
+	return file_screenshoter_v1_screenshoter_proto_rawDescGZIP(), []int{0}
+}
+
+func (x *Viewport) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Viewport) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+type Selection struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	X             int32                  `protobuf:"varint,1,opt,name=x,proto3" json:"x,omitempty"`
+	Y             int32                  `protobuf:"varint,2,opt,name=y,proto3" json:"y,omitempty"`
+	Width         int32                  `protobuf:"varint,3,opt,name=width,proto3" json:"width,omitempty"`
+	Height        int32                  `protobuf:"varint,4,opt,name=height,proto3" json:"height,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Selection) Reset() {
+	*x = Selection{}
+	mi := &file_screenshoter_v1_screenshoter_proto_msgTypes[1]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Selection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Selection) ProtoMessage() {}
+
+func (x *Selection) ProtoReflect() protoreflect.Message {
+	mi := &file_screenshoter_v1_screenshoter_proto_msgTypes[1]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Selection.ProtoReflect.Descriptor instead.
+func (*Selection) Descriptor() ([]byte, []int) {
+	return file_screenshoter_v1_screenshoter_proto_rawDescGZIP(), []int{1}
+}
+
+func (x *Selection) GetX() int32 {
+	if x != nil {
+		return x.X
+	}
+	return 0
+}
+
+func (x *Selection) GetY() int32 {
+	if x != nil {
+		return x.Y
+	}
+	return 0
+}
+
+func (x *Selection) GetWidth() int32 {
+	if x != nil {
+		return x.Width
+	}
+	return 0
+}
+
+func (x *Selection) GetHeight() int32 {
+	if x != nil {
+		return x.Height
+	}
+	return 0
+}
+
+type SelectionStyle struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	BorderColor   string                 `protobuf:"bytes,1,opt,name=border_color,json=borderColor,proto3" json:"border_color,omitempty"`
+	BorderWidth   int32                  `protobuf:"varint,2,opt,name=border_width,json=borderWidth,proto3" json:"border_width,omitempty"`
+	BorderStyle   string                 `protobuf:"bytes,3,opt,name=border_style,json=borderStyle,proto3" json:"border_style,omitempty"` // solid, dashed, dotted
+	Opacity       float64                `protobuf:"fixed64,4,opt,name=opacity,proto3" json:"opacity,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SelectionStyle) Reset() {
+	*x = SelectionStyle{}
+	mi := &file_screenshoter_v1_screenshoter_proto_msgTypes[2]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SelectionStyle) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SelectionStyle) ProtoMessage() {}
+
+func (x *SelectionStyle) ProtoReflect() protoreflect.Message {
+	mi := &file_screenshoter_v1_screenshoter_proto_msgTypes[2]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SelectionStyle.ProtoReflect.Descriptor instead.
+func (*SelectionStyle) Descriptor() ([]byte, []int) {
+	return file_screenshoter_v1_screenshoter_proto_rawDescGZIP(), []int{2}
+}
+
+func (x *SelectionStyle) GetBorderColor() string {
+	if x != nil {
+		return x.BorderColor
+	}
+	return ""
+}
+
+func (x *SelectionStyle) GetBorderWidth() int32 {
+	if x != nil {
+		return x.BorderWidth
+	}
+	return 0
+}
+
+func (x *SelectionStyle) GetBorderStyle() string {
+	if x != nil {
+		return x.BorderStyle
+	}
+	return ""
+}
+
+func (x *SelectionStyle) GetOpacity() float64 {
+	if x != nil {
+		return x.Opacity
+	}
+	return 0
+}
+
+// ScreenshotOptions соответствует service.ScreenshotOptions, незаданные поля берутся из настроек сервера
+type ScreenshotOptions struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Browser        Browser                `protobuf:"varint,1,opt,name=browser,proto3,enum=screenshoter.v1.Browser" json:"browser,omitempty"`
+	Quality        *int32                 `protobuf:"varint,2,opt,name=quality,proto3,oneof" json:"quality,omitempty"`
+	Type           string                 `protobuf:"bytes,3,opt,name=type,proto3" json:"type,omitempty"` // png, jpeg
+	FullPage       *bool                  `protobuf:"varint,4,opt,name=full_page,json=fullPage,proto3,oneof" json:"full_page,omitempty"`
+	OmitBackground bool                   `protobuf:"varint,5,opt,name=omit_background,json=omitBackground,proto3" json:"omit_background,omitempty"`
+	Viewport       *Viewport              `protobuf:"bytes,6,opt,name=viewport,proto3" json:"viewport,omitempty"`
+	Timeout        *float64               `protobuf:"fixed64,7,opt,name=timeout,proto3,oneof" json:"timeout,omitempty"` // мс
+	Selections     []*Selection           `protobuf:"bytes,8,rep,name=selections,proto3" json:"selections,omitempty"`
+	SelectionStyle *SelectionStyle        `protobuf:"bytes,9,opt,name=selection_style,json=selectionStyle,proto3" json:"selection_style,omitempty"`
+	ScrollX        int32                  `protobuf:"varint,10,opt,name=scroll_x,json=scrollX,proto3" json:"scroll_x,omitempty"`
+	ScrollY        int32                  `protobuf:"varint,11,opt,name=scroll_y,json=scrollY,proto3" json:"scroll_y,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *ScreenshotOptions) Reset() {
+	*x = ScreenshotOptions{}
+	mi := &file_screenshoter_v1_screenshoter_proto_msgTypes[3]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ScreenshotOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ScreenshotOptions) ProtoMessage() {}
+
+func (x *ScreenshotOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_screenshoter_v1_screenshoter_proto_msgTypes[3]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ScreenshotOptions.ProtoReflect.Descriptor instead.
+func (*ScreenshotOptions) Descriptor() ([]byte, []int) {
+	return file_screenshoter_v1_screenshoter_proto_rawDescGZIP(), []int{3}
+}
+
+func (x *ScreenshotOptions) GetBrowser() Browser {
+	if x != nil {
+		return x.Browser
+	}
+	return Browser_BROWSER_UNSPECIFIED
+}
+
+func (x *ScreenshotOptions) GetQuality() int32 {
+	if x != nil && x.Quality != nil {
+		return *x.Quality
+	}
+	return 0
+}
+
+func (x *ScreenshotOptions) GetType() string {
+	if x != nil {
+		return x.Type
+	}
+	return ""
+}
+
+func (x *ScreenshotOptions) GetFullPage() bool {
+	if x != nil && x.FullPage != nil {
+		return *x.FullPage
+	}
+	return false
+}
+
+func (x *ScreenshotOptions) GetOmitBackground() bool {
+	if x != nil {
+		return x.OmitBackground
+	}
+	return false
+}
+
+func (x *ScreenshotOptions) GetViewport() *Viewport {
+	if x != nil {
+		return x.Viewport
+	}
+	return nil
+}
+
+func (x *ScreenshotOptions) GetTimeout() float64 {
+	if x != nil && x.Timeout != nil {
+		return *x.Timeout
+	}
+	return 0
+}
+
+func (x *ScreenshotOptions) GetSelections() []*Selection {
+	if x != nil {
+		return x.Selections
+	}
+	return nil
+}
+
+func (x *ScreenshotOptions) GetSelectionStyle() *SelectionStyle {
+	if x != nil {
+		return x.SelectionStyle
+	}
+	return nil
+}
+
+func (x *ScreenshotOptions) GetScrollX() int32 {
+	if x != nil {
+		return x.ScrollX
+	}
+	return 0
+}
+
+func (x *ScreenshotOptions) GetScrollY() int32 {
+	if x != nil {
+		return x.ScrollY
+	}
+	return 0
+}
+
+type CaptureRequest struct {
+	state   protoimpl.MessageState `protogen:"open.v1"`
+	Html    string                 `protobuf:"bytes,1,opt,name=html,proto3" json:"html,omitempty"`
+	Options *ScreenshotOptions     `protobuf:"bytes,2,opt,name=options,proto3" json:"options,omitempty"`
+	// Не ждать результата: вернуть job_id и забирать результат через GetJob (только для Capture)
+	Async         bool `protobuf:"varint,3,opt,name=async,proto3" json:"async,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureRequest) Reset() {
+	*x = CaptureRequest{}
+	mi := &file_screenshoter_v1_screenshoter_proto_msgTypes[4]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureRequest) ProtoMessage() {}
+
+func (x *CaptureRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_screenshoter_v1_screenshoter_proto_msgTypes[4]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureRequest.ProtoReflect.Descriptor instead.
+func (*CaptureRequest) Descriptor() ([]byte, []int) {
+	return file_screenshoter_v1_screenshoter_proto_rawDescGZIP(), []int{4}
+}
+
+func (x *CaptureRequest) GetHtml() string {
+	if x != nil {
+		return x.Html
+	}
+	return ""
+}
+
+func (x *CaptureRequest) GetOptions() *ScreenshotOptions {
+	if x != nil {
+		return x.Options
+	}
+	return nil
+}
+
+func (x *CaptureRequest) GetAsync() bool {
+	if x != nil {
+		return x.Async
+	}
+	return false
+}
+
+type CaptureResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"` // только при async = true
+	ContentType   string                 `protobuf:"bytes,2,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Image         []byte                 `protobuf:"bytes,3,opt,name=image,proto3" json:"image,omitempty"` // пусто при async = true
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureResponse) Reset() {
+	*x = CaptureResponse{}
+	mi := &file_screenshoter_v1_screenshoter_proto_msgTypes[5]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureResponse) ProtoMessage() {}
+
+func (x *CaptureResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_screenshoter_v1_screenshoter_proto_msgTypes[5]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureResponse.ProtoReflect.Descriptor instead.
+func (*CaptureResponse) Descriptor() ([]byte, []int) {
+	return file_screenshoter_v1_screenshoter_proto_rawDescGZIP(), []int{5}
+}
+
+func (x *CaptureResponse) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+func (x *CaptureResponse) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *CaptureResponse) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+type CaptureChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	ContentType   string                 `protobuf:"bytes,1,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"` // только в первой части
+	TotalSize     int64                  `protobuf:"varint,2,opt,name=total_size,json=totalSize,proto3" json:"total_size,omitempty"`      // только в первой части
+	Data          []byte                 `protobuf:"bytes,3,opt,name=data,proto3" json:"data,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CaptureChunk) Reset() {
+	*x = CaptureChunk{}
+	mi := &file_screenshoter_v1_screenshoter_proto_msgTypes[6]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CaptureChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CaptureChunk) ProtoMessage() {}
+
+func (x *CaptureChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_screenshoter_v1_screenshoter_proto_msgTypes[6]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CaptureChunk.ProtoReflect.Descriptor instead.
+func (*CaptureChunk) Descriptor() ([]byte, []int) {
+	return file_screenshoter_v1_screenshoter_proto_rawDescGZIP(), []int{6}
+}
+
+func (x *CaptureChunk) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *CaptureChunk) GetTotalSize() int64 {
+	if x != nil {
+		return x.TotalSize
+	}
+	return 0
+}
+
+func (x *CaptureChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+type GetJobRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	JobId         string                 `protobuf:"bytes,1,opt,name=job_id,json=jobId,proto3" json:"job_id,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetJobRequest) Reset() {
+	*x = GetJobRequest{}
+	mi := &file_screenshoter_v1_screenshoter_proto_msgTypes[7]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetJobRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetJobRequest) ProtoMessage() {}
+
+func (x *GetJobRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_screenshoter_v1_screenshoter_proto_msgTypes[7]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetJobRequest.ProtoReflect.Descriptor instead.
+func (*GetJobRequest) Descriptor() ([]byte, []int) {
+	return file_screenshoter_v1_screenshoter_proto_rawDescGZIP(), []int{7}
+}
+
+func (x *GetJobRequest) GetJobId() string {
+	if x != nil {
+		return x.JobId
+	}
+	return ""
+}
+
+type Job struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Status        JobStatus              `protobuf:"varint,2,opt,name=status,proto3,enum=screenshoter.v1.JobStatus" json:"status,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	ContentType   string                 `protobuf:"bytes,4,opt,name=content_type,json=contentType,proto3" json:"content_type,omitempty"`
+	Image         []byte                 `protobuf:"bytes,5,opt,name=image,proto3" json:"image,omitempty"`
+	CreatedAt     *timestamppb.Timestamp `protobuf:"bytes,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	FinishedAt    *timestamppb.Timestamp `protobuf:"bytes,7,opt,name=finished_at,json=finishedAt,proto3" json:"finished_at,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *Job) Reset() {
+	*x = Job{}
+	mi := &file_screenshoter_v1_screenshoter_proto_msgTypes[8]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *Job) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*Job) ProtoMessage() {}
+
+func (x *Job) ProtoReflect() protoreflect.Message {
+	mi := &file_screenshoter_v1_screenshoter_proto_msgTypes[8]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use Job.ProtoReflect.Descriptor instead.
+func (*Job) Descriptor() ([]byte, []int) {
+	return file_screenshoter_v1_screenshoter_proto_rawDescGZIP(), []int{8}
+}
+
+func (x *Job) GetId() string {
+	if x != nil {
+		return x.Id
+	}
+	return ""
+}
+
+func (x *Job) GetStatus() JobStatus {
+	if x != nil {
+		return x.Status
+	}
+	return JobStatus_JOB_STATUS_UNSPECIFIED
+}
+
+func (x *Job) GetError() string {
+	if x != nil {
+		return x.Error
+	}
+	return ""
+}
+
+func (x *Job) GetContentType() string {
+	if x != nil {
+		return x.ContentType
+	}
+	return ""
+}
+
+func (x *Job) GetImage() []byte {
+	if x != nil {
+		return x.Image
+	}
+	return nil
+}
+
+func (x *Job) GetCreatedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return nil
+}
+
+func (x *Job) GetFinishedAt() *timestamppb.Timestamp {
+	if x != nil {
+		return x.FinishedAt
+	}
+	return nil
+}
+
+var File_screenshoter_v1_screenshoter_proto protoreflect.FileDescriptor
+
+const file_screenshoter_v1_screenshoter_proto_rawDesc = "" +
+	"\n" +
+	"\"screenshoter/v1/screenshoter.proto\x12\x0fscreenshoter.v1\x1a\x1fgoogle/protobuf/timestamp.proto\"8\n" +
+	"\bViewport\x12\x14\n" +
+	"\x05width\x18\x01 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x02 \x01(\x05R\x06height\"U\n" +
+	"\tSelection\x12\f\n" +
+	"\x01x\x18\x01 \x01(\x05R\x01x\x12\f\n" +
+	"\x01y\x18\x02 \x01(\x05R\x01y\x12\x14\n" +
+	"\x05width\x18\x03 \x01(\x05R\x05width\x12\x16\n" +
+	"\x06height\x18\x04 \x01(\x05R\x06height\"\x93\x01\n" +
+	"\x0eSelectionStyle\x12!\n" +
+	"\fborder_color\x18\x01 \x01(\tR\vborderColor\x12!\n" +
+	"\fborder_width\x18\x02 \x01(\x05R\vborderWidth\x12!\n" +
+	"\fborder_style\x18\x03 \x01(\tR\vborderStyle\x12\x18\n" +
+	"\aopacity\x18\x04 \x01(\x01R\aopacity\"\xfd\x03\n" +
+	"\x11ScreenshotOptions\x122\n" +
+	"\abrowser\x18\x01 \x01(\x0e2\x18.screenshoter.v1.BrowserR\abrowser\x12\x1d\n" +
+	"\aquality\x18\x02 \x01(\x05H\x00R\aquality\x88\x01\x01\x12\x12\n" +
+	"\x04type\x18\x03 \x01(\tR\x04type\x12 \n" +
+	"\tfull_page\x18\x04 \x01(\bH\x01R\bfullPage\x88\x01\x01\x12'\n" +
+	"\x0fomit_background\x18\x05 \x01(\bR\x0eomitBackground\x125\n" +
+	"\bviewport\x18\x06 \x01(\v2\x19.screenshoter.v1.ViewportR\bviewport\x12\x1d\n" +
+	"\atimeout\x18\a \x01(\x01H\x02R\atimeout\x88\x01\x01\x12:\n" +
+	"\n" +
+	"selections\x18\b \x03(\v2\x1a.screenshoter.v1.SelectionR\n" +
+	"selections\x12H\n" +
+	"\x0fselection_style\x18\t \x01(\v2\x1f.screenshoter.v1.SelectionStyleR\x0eselectionStyle\x12\x19\n" +
+	"\bscroll_x\x18\n" +
+	" \x01(\x05R\ascrollX\x12\x19\n" +
+	"\bscroll_y\x18\v \x01(\x05R\ascrollYB\n" +
+	"\n" +
+	"\b_qualityB\f\n" +
+	"\n" +
+	"_full_pageB\n" +
+	"\n" +
+	"\b_timeout\"x\n" +
+	"\x0eCaptureRequest\x12\x12\n" +
+	"\x04html\x18\x01 \x01(\tR\x04html\x12<\n" +
+	"\aoptions\x18\x02 \x01(\v2\".screenshoter.v1.ScreenshotOptionsR\aoptions\x12\x14\n" +
+	"\x05async\x18\x03 \x01(\bR\x05async\"a\n" +
+	"\x0fCaptureResponse\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\x12!\n" +
+	"\fcontent_type\x18\x02 \x01(\tR\vcontentType\x12\x14\n" +
+	"\x05image\x18\x03 \x01(\fR\x05image\"d\n" +
+	"\fCaptureChunk\x12!\n" +
+	"\fcontent_type\x18\x01 \x01(\tR\vcontentType\x12\x1d\n" +
+	"\n" +
+	"total_size\x18\x02 \x01(\x03R\ttotalSize\x12\x12\n" +
+	"\x04data\x18\x03 \x01(\fR\x04data\"&\n" +
+	"\rGetJobRequest\x12\x15\n" +
+	"\x06job_id\x18\x01 \x01(\tR\x05jobId\"\x90\x02\n" +
+	"\x03Job\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x122\n" +
+	"\x06status\x18\x02 \x01(\x0e2\x1a.screenshoter.v1.JobStatusR\x06status\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\x12!\n" +
+	"\fcontent_type\x18\x04 \x01(\tR\vcontentType\x12\x14\n" +
+	"\x05image\x18\x05 \x01(\fR\x05image\x129\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\v2\x1a.google.protobuf.TimestampR\tcreatedAt\x12;\n" +
+	"\vfinished_at\x18\a \x01(\v2\x1a.google.protobuf.TimestampR\n" +
+	"finishedAt*a\n" +
+	"\aBrowser\x12\x17\n" +
+	"\x13BROWSER_UNSPECIFIED\x10\x00\x12\x14\n" +
+	"\x10BROWSER_CHROMIUM\x10\x01\x12\x13\n" +
+	"\x0fBROWSER_FIREFOX\x10\x02\x12\x12\n" +
+	"\x0eBROWSER_WEBKIT\x10\x03*k\n" +
+	"\tJobStatus\x12\x1a\n" +
+	"\x16JOB_STATUS_UNSPECIFIED\x10\x00\x12\x16\n" +
+	"\x12JOB_STATUS_RUNNING\x10\x01\x12\x13\n" +
+	"\x0fJOB_STATUS_DONE\x10\x02\x12\x15\n" +
+	"\x11JOB_STATUS_FAILED\x10\x032\xef\x01\n" +
+	"\fScreenshoter\x12L\n" +
+	"\aCapture\x12\x1f.screenshoter.v1.CaptureRequest\x1a .screenshoter.v1.CaptureResponse\x12Q\n" +
+	"\rCaptureStream\x12\x1f.screenshoter.v1.CaptureRequest\x1a\x1d.screenshoter.v1.CaptureChunk0\x01\x12>\n" +
+	"\x06GetJob\x12\x1e.screenshoter.v1.GetJobRequest\x1a\x14.screenshoter.v1.JobB5Z3screenshoter/pkg/api/screenshoter/v1;screenshoterv1b\x06proto3"
+
+var (
+	file_screenshoter_v1_screenshoter_proto_rawDescOnce sync.Once
+	file_screenshoter_v1_screenshoter_proto_rawDescData []byte
+)
+
+func file_screenshoter_v1_screenshoter_proto_rawDescGZIP() []byte {
+	file_screenshoter_v1_screenshoter_proto_rawDescOnce.Do(func() {
+		file_screenshoter_v1_screenshoter_proto_rawDescData = protoimpl.X.CompressGZIP(unsafe.Slice(unsafe.StringData(file_screenshoter_v1_screenshoter_proto_rawDesc), len(file_screenshoter_v1_screenshoter_proto_rawDesc)))
+	})
+	return file_screenshoter_v1_screenshoter_proto_rawDescData
+}
+
+var file_screenshoter_v1_screenshoter_proto_enumTypes = make([]protoimpl.EnumInfo, 2)
+var file_screenshoter_v1_screenshoter_proto_msgTypes = make([]protoimpl.MessageInfo, 9)
+var file_screenshoter_v1_screenshoter_proto_goTypes = []any{
+	(Browser)(0),                  // 0: screenshoter.v1.Browser
+	(JobStatus)(0),                // 1: screenshoter.v1.JobStatus
+	(*Viewport)(nil),              // 2: screenshoter.v1.Viewport
+	(*Selection)(nil),             // 3: screenshoter.v1.Selection
+	(*SelectionStyle)(nil),        // 4: screenshoter.v1.SelectionStyle
+	(*ScreenshotOptions)(nil),     // 5: screenshoter.v1.ScreenshotOptions
+	(*CaptureRequest)(nil),        // 6: screenshoter.v1.CaptureRequest
+	(*CaptureResponse)(nil),       // 7: screenshoter.v1.CaptureResponse
+	(*CaptureChunk)(nil),          // 8: screenshoter.v1.CaptureChunk
+	(*GetJobRequest)(nil),         // 9: screenshoter.v1.GetJobRequest
+	(*Job)(nil),                   // 10: screenshoter.v1.Job
+	(*timestamppb.Timestamp)(nil), // 11: google.protobuf.Timestamp
+}
+var file_screenshoter_v1_screenshoter_proto_depIdxs = []int32{
+	0,  // 0: screenshoter.v1.ScreenshotOptions.browser:type_name -> screenshoter.v1.Browser
+	2,  // 1: screenshoter.v1.ScreenshotOptions.viewport:type_name -> screenshoter.v1.Viewport
+	3,  // 2: screenshoter.v1.ScreenshotOptions.selections:type_name -> screenshoter.v1.Selection
+	4,  // 3: screenshoter.v1.ScreenshotOptions.selection_style:type_name -> screenshoter.v1.SelectionStyle
+	5,  // 4: screenshoter.v1.CaptureRequest.options:type_name -> screenshoter.v1.ScreenshotOptions
+	1,  // 5: screenshoter.v1.Job.status:type_name -> screenshoter.v1.JobStatus
+	11, // 6: screenshoter.v1.Job.created_at:type_name -> google.protobuf.Timestamp
+	11, // 7: screenshoter.v1.Job.finished_at:type_name -> google.protobuf.Timestamp
+	6,  // 8: screenshoter.v1.Screenshoter.Capture:input_type -> screenshoter.v1.CaptureRequest
+	6,  // 9: screenshoter.v1.Screenshoter.CaptureStream:input_type -> screenshoter.v1.CaptureRequest
+	9,  // 10: screenshoter.v1.Screenshoter.GetJob:input_type -> screenshoter.v1.GetJobRequest
+	7,  // 11: screenshoter.v1.Screenshoter.Capture:output_type -> screenshoter.v1.CaptureResponse
+	8,  // 12: screenshoter.v1.Screenshoter.CaptureStream:output_type -> screenshoter.v1.CaptureChunk
+	10, // 13: screenshoter.v1.Screenshoter.GetJob:output_type -> screenshoter.v1.Job
+	11, // [11:14] is the sub-list for method output_type
+	8,  // [8:11] is the sub-list for method input_type
+	8,  // [8:8] is the sub-list for extension type_name
+	8,  // [8:8] is the sub-list for extension extendee
+	0,  // [0:8] is the sub-list for field type_name
+}
+
+func init() { file_screenshoter_v1_screenshoter_proto_init() }
+func file_screenshoter_v1_screenshoter_proto_init() {
+	if File_screenshoter_v1_screenshoter_proto != nil {
+		return
+	}
+	file_screenshoter_v1_screenshoter_proto_msgTypes[3].OneofWrappers = []any{}
+	type x struct{}
+	out := protoimpl.TypeBuilder{
+		File: protoimpl.DescBuilder{
+			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
+			RawDescriptor: unsafe.Slice(unsafe.StringData(file_screenshoter_v1_screenshoter_proto_rawDesc), len(file_screenshoter_v1_screenshoter_proto_rawDesc)),
+			NumEnums:      2,
+			NumMessages:   9,
+			NumExtensions: 0,
+			NumServices:   1,
+		},
+		GoTypes:           file_screenshoter_v1_screenshoter_proto_goTypes,
+		DependencyIndexes: file_screenshoter_v1_screenshoter_proto_depIdxs,
+		EnumInfos:         file_screenshoter_v1_screenshoter_proto_enumTypes,
+		MessageInfos:      file_screenshoter_v1_screenshoter_proto_msgTypes,
+	}.Build()
+	File_screenshoter_v1_screenshoter_proto = out.File
+	file_screenshoter_v1_screenshoter_proto_goTypes = nil
+	file_screenshoter_v1_screenshoter_proto_depIdxs = nil
+}
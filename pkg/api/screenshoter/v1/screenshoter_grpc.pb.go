@@ -0,0 +1,211 @@
+// Code generated by protoc-gen-go-grpc. DO NOT EDIT.
+// versions:
+// - protoc-gen-go-grpc v1.6.2
+// - protoc             (unknown)
+// source: screenshoter/v1/screenshoter.proto
+
+package screenshoterv1
+
+import (
+	context "context"
+	grpc "google.golang.org/grpc"
+	codes "google.golang.org/grpc/codes"
+	status "google.golang.org/grpc/status"
+)
+
+// This is a compile-time assertion to ensure that this generated file
+// is compatible with the grpc package it is being compiled against.
+// Requires gRPC-Go v1.64.0 or later.
+const _ = grpc.SupportPackageIsVersion9
+
+const (
+	Screenshoter_Capture_FullMethodName       = "/screenshoter.v1.Screenshoter/Capture"
+	Screenshoter_CaptureStream_FullMethodName = "/screenshoter.v1.Screenshoter/CaptureStream"
+	Screenshoter_GetJob_FullMethodName        = "/screenshoter.v1.Screenshoter/GetJob"
+)
+
+// ScreenshoterClient is the client API for Screenshoter service.
+//
+// For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
+//
+// Screenshoter рендер скриншотов из html
+type ScreenshoterClient interface {
+	// Capture делает скриншот и возвращает его целиком (или идентификатор задачи при async = true)
+	Capture(ctx context.Context, in *CaptureRequest, opts ...grpc.CallOption) (*CaptureResponse, error)
+	// CaptureStream делает скриншот и отдаёт его частями
+	CaptureStream(ctx context.Context, in *CaptureRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CaptureChunk], error)
+	// GetJob возвращает состояние задачи, созданной через Capture
+	GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error)
+}
+
+type screenshoterClient struct {
+	cc grpc.ClientConnInterface
+}
+
+func NewScreenshoterClient(cc grpc.ClientConnInterface) ScreenshoterClient {
+	return &screenshoterClient{cc}
+}
+
+func (c *screenshoterClient) Capture(ctx context.Context, in *CaptureRequest, opts ...grpc.CallOption) (*CaptureResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CaptureResponse)
+	err := c.cc.Invoke(ctx, Screenshoter_Capture_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *screenshoterClient) CaptureStream(ctx context.Context, in *CaptureRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[CaptureChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &Screenshoter_ServiceDesc.Streams[0], Screenshoter_CaptureStream_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[CaptureRequest, CaptureChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Screenshoter_CaptureStreamClient = grpc.ServerStreamingClient[CaptureChunk]
+
+func (c *screenshoterClient) GetJob(ctx context.Context, in *GetJobRequest, opts ...grpc.CallOption) (*Job, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Job)
+	err := c.cc.Invoke(ctx, Screenshoter_GetJob_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+// ScreenshoterServer is the server API for Screenshoter service.
+// All implementations must embed UnimplementedScreenshoterServer
+// for forward compatibility.
+//
+// Screenshoter рендер скриншотов из html
+type ScreenshoterServer interface {
+	// Capture делает скриншот и возвращает его целиком (или идентификатор задачи при async = true)
+	Capture(context.Context, *CaptureRequest) (*CaptureResponse, error)
+	// CaptureStream делает скриншот и отдаёт его частями
+	CaptureStream(*CaptureRequest, grpc.ServerStreamingServer[CaptureChunk]) error
+	// GetJob возвращает состояние задачи, созданной через Capture
+	GetJob(context.Context, *GetJobRequest) (*Job, error)
+	mustEmbedUnimplementedScreenshoterServer()
+}
+
+// UnimplementedScreenshoterServer must be embedded to have
+// forward compatible implementations.
+//
+// NOTE: this should be embedded by value instead of pointer to avoid a nil
+// pointer dereference when methods are called.
+type UnimplementedScreenshoterServer struct{}
+
+func (UnimplementedScreenshoterServer) Capture(context.Context, *CaptureRequest) (*CaptureResponse, error) {
+	return nil, status.Error(codes.Unimplemented, "method Capture not implemented")
+}
+func (UnimplementedScreenshoterServer) CaptureStream(*CaptureRequest, grpc.ServerStreamingServer[CaptureChunk]) error {
+	return status.Error(codes.Unimplemented, "method CaptureStream not implemented")
+}
+func (UnimplementedScreenshoterServer) GetJob(context.Context, *GetJobRequest) (*Job, error) {
+	return nil, status.Error(codes.Unimplemented, "method GetJob not implemented")
+}
+func (UnimplementedScreenshoterServer) mustEmbedUnimplementedScreenshoterServer() {}
+func (UnimplementedScreenshoterServer) testEmbeddedByValue()                      {}
+
+// UnsafeScreenshoterServer may be embedded to opt out of forward compatibility for this service.
+// Use of this interface is not recommended, as added methods to ScreenshoterServer will
+// result in compilation errors.
+type UnsafeScreenshoterServer interface {
+	mustEmbedUnimplementedScreenshoterServer()
+}
+
+func RegisterScreenshoterServer(s grpc.ServiceRegistrar, srv ScreenshoterServer) {
+	// If the following call panics, it indicates UnimplementedScreenshoterServer was
+	// embedded by pointer and is nil.  This will cause panics if an
+	// unimplemented method is ever invoked, so we test this at initialization
+	// time to prevent it from happening at runtime later due to I/O.
+	if t, ok := srv.(interface{ testEmbeddedByValue() }); ok {
+		t.testEmbeddedByValue()
+	}
+	s.RegisterService(&Screenshoter_ServiceDesc, srv)
+}
+
+func _Screenshoter_Capture_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CaptureRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScreenshoterServer).Capture(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Screenshoter_Capture_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScreenshoterServer).Capture(ctx, req.(*CaptureRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _Screenshoter_CaptureStream_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(CaptureRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(ScreenshoterServer).CaptureStream(m, &grpc.GenericServerStream[CaptureRequest, CaptureChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type Screenshoter_CaptureStreamServer = grpc.ServerStreamingServer[CaptureChunk]
+
+func _Screenshoter_GetJob_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetJobRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(ScreenshoterServer).GetJob(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: Screenshoter_GetJob_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(ScreenshoterServer).GetJob(ctx, req.(*GetJobRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+// Screenshoter_ServiceDesc is the grpc.ServiceDesc for Screenshoter service.
+// It's only intended for direct use with grpc.RegisterService,
+// and not to be introspected or modified (even as a copy)
+var Screenshoter_ServiceDesc = grpc.ServiceDesc{
+	ServiceName: "screenshoter.v1.Screenshoter",
+	HandlerType: (*ScreenshoterServer)(nil),
+	Methods: []grpc.MethodDesc{
+		{
+			MethodName: "Capture",
+			Handler:    _Screenshoter_Capture_Handler,
+		},
+		{
+			MethodName: "GetJob",
+			Handler:    _Screenshoter_GetJob_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "CaptureStream",
+			Handler:       _Screenshoter_CaptureStream_Handler,
+			ServerStreams: true,
+		},
+	},
+	Metadata: "screenshoter/v1/screenshoter.proto",
+}
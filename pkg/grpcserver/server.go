@@ -0,0 +1,46 @@
+package grpcserver
+
+import (
+	"context"
+	"net"
+
+	"google.golang.org/grpc"
+)
+
+type Server struct {
+	grpcServer *grpc.Server
+}
+
+func NewServer(opts ...grpc.ServerOption) *Server {
+	return &Server{grpcServer: grpc.NewServer(opts...)}
+}
+
+// Registrar используется для регистрации сервисов до запуска сервера
+func (s *Server) Registrar() grpc.ServiceRegistrar {
+	return s.grpcServer
+}
+
+func (s *Server) Run(port string) error {
+	lis, err := net.Listen("tcp", ":"+port)
+	if err != nil {
+		return err
+	}
+	return s.grpcServer.Serve(lis)
+}
+
+// Stop дожидается завершения активных вызовов, по истечении ctx прерывает их
+func (s *Server) Stop(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		s.grpcServer.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		s.grpcServer.Stop()
+		return ctx.Err()
+	}
+}
@@ -0,0 +1,50 @@
+package logger
+
+import "context"
+
+type loggerKey struct{}
+
+type requestIDKey struct{}
+
+type errorKey struct{}
+
+// NewContext возвращает контекст с логгером запроса
+func NewContext(ctx context.Context, l *Logger) context.Context {
+	return context.WithValue(ctx, loggerKey{}, l)
+}
+
+// FromContext логгер запроса из контекста, если его нет - fallback
+func FromContext(ctx context.Context, fallback *Logger) *Logger {
+	if l, ok := ctx.Value(loggerKey{}).(*Logger); ok {
+		return l
+	}
+	return fallback
+}
+
+// Rebind привязывает логгер запроса к ctx, чтобы его события видели добавленный позже hub Sentry
+func Rebind(ctx context.Context, fallback *Logger) context.Context {
+	l := FromContext(ctx, fallback)
+	return NewContext(ctx, &Logger{Logger: l.With().Ctx(ctx).Logger()})
+}
+
+// WithRequestID возвращает контекст с идентификатором запроса
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestID идентификатор запроса из контекста, пустая строка если его нет
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// withError возвращает контекст с ошибкой события для SentryHook
+func withError(ctx context.Context, err error) context.Context {
+	return context.WithValue(ctx, errorKey{}, err)
+}
+
+// errorFromContext ошибка события, переданная через Exception
+func errorFromContext(ctx context.Context) error {
+	err, _ := ctx.Value(errorKey{}).(error)
+	return err
+}
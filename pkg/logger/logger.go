@@ -45,16 +45,36 @@ func (l logWriter) WriteLevel(level zerolog.Level, p []byte) (n int, err error)
 // SentryHook - хук для zerolog, отправляющий ошибки в Sentry
 type SentryHook struct{}
 
+// Ошибка, переданная через Logger.Exception, отправляется целиком с цепочкой причин и стеком.
+// Используется hub запроса из контекста события, если он есть
 func (h SentryHook) Run(e *zerolog.Event, level zerolog.Level, msg string) {
 	if level >= zerolog.ErrorLevel { // Отправляем только ошибки и выше (Fatal, Panic)
-		sentry.WithScope(func(scope *sentry.Scope) {
+		ctx := e.GetCtx()
+		hub := sentry.GetHubFromContext(ctx)
+		if hub == nil {
+			hub = sentry.CurrentHub()
+		}
+		hub.WithScope(func(scope *sentry.Scope) {
 			scope.SetLevel(sentryLevel(level))
 			scope.SetExtra("message", msg)
-			sentry.CaptureMessage(msg)
+			if id := RequestID(ctx); id != "" {
+				scope.SetTag("request_id", id)
+			}
+			if err := errorFromContext(ctx); err != nil {
+				hub.CaptureException(err)
+				return
+			}
+			hub.CaptureMessage(msg)
 		})
 	}
 }
 
+// Exception событие уровня Error с ошибкой, которая уходит в Sentry через CaptureException
+func (l *Logger) Exception(err error) *zerolog.Event {
+	e := l.Error()
+	return e.Ctx(withError(e.GetCtx(), err)).Err(err)
+}
+
 // Конвертирует уровень zerolog в уровень Sentry
 func sentryLevel(level zerolog.Level) sentry.Level {
 	switch level {
@@ -0,0 +1,35 @@
+package logger
+
+import (
+	"fmt"
+	"runtime"
+)
+
+// PanicError перехваченная паника со стеком в месте паники.
+// Sentry получает стек через метод StackTrace
+type PanicError struct {
+	value interface{}
+	pcs   []uintptr
+}
+
+// NewPanicError ошибка из значения recover(), вызывается непосредственно в отложенной функции
+func NewPanicError(value interface{}) *PanicError {
+	pcs := make([]uintptr, 64)
+	// Пропускаем runtime.Callers, NewPanicError и отложенную функцию
+	n := runtime.Callers(3, pcs)
+	return &PanicError{value: value, pcs: pcs[:n]}
+}
+
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v", e.value)
+}
+
+// Unwrap исходная ошибка, если паника была вызвана значением error
+func (e *PanicError) Unwrap() error {
+	err, _ := e.value.(error)
+	return err
+}
+
+func (e *PanicError) StackTrace() []uintptr {
+	return e.pcs
+}